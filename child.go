@@ -0,0 +1,88 @@
+package inject
+
+import "reflect"
+
+// NewChild 创建一个以g为父级的依赖图。子依赖图中找不到的类型和名称会在父级中查找，
+// 子依赖图可以提供与父级相同类型或名称的对象来覆盖父级的对象，而父级看不到子依赖图中的对象。
+func (g *Graph) NewChild() *Graph {
+	return &Graph{
+		Logger:           g.Logger,
+		CollectAllErrors: g.CollectAllErrors,
+		parent:           g,
+	}
+}
+
+// owns 返回o是否是直接提供给g的对象，而不是从父级找到的。
+func (g *Graph) owns(o *Object) bool {
+	if o.Name != "" {
+		return g.named[o.Name] == o
+	}
+	if !o.reflectType.Comparable() {
+		for _, existing := range g.unnamed {
+			if existing == o {
+				return true
+			}
+		}
+		return false
+	}
+	return g.provided(o.Value)
+}
+
+// hasConstructor 返回g自己是否有结果可分配给t的构造函数，这样的构造函数会覆盖父级中的对象。
+func (g *Graph) hasConstructor(t reflect.Type) bool {
+	for _, c := range g.constructors {
+		if c.out.AssignableTo(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// inherits 返回对t的查找是否应该交给父级，即g自己既没有可分配的对象也没有可分配的构造函数。
+func (g *Graph) inherits(t reflect.Type) bool {
+	return g.parent != nil && len(g.candidates(t)) == 0 && !g.hasConstructor(t)
+}
+
+// lookupNamed 查找指定名称的对象，子依赖图中的名称优先于父级。
+func (g *Graph) lookupNamed(name string) *Object {
+	for ; g != nil; g = g.parent {
+		if o := g.named[name]; o != nil {
+			return o
+		}
+	}
+	return nil
+}
+
+// namedObjects 返回包括父级在内的所有命名对象，子依赖图中的名称覆盖父级。
+func (g *Graph) namedObjects() map[string]*Object {
+	if g.parent == nil {
+		return g.named
+	}
+	objects := g.parent.namedObjects()
+	merged := make(map[string]*Object, len(objects)+len(g.named))
+	for name, o := range objects {
+		merged[name] = o
+	}
+	for name, o := range g.named {
+		merged[name] = o
+	}
+	return merged
+}
+
+// constructInOwner 在提供单例构造函数c的祖先依赖图中调用它并完成注入，其结果只依赖该祖先能看到的对象。
+// 原型和请求作用域的构造函数则在需要它们的依赖图中调用。
+func (g *Graph) constructInOwner(c *Constructor) (*Object, error) {
+	owner := c.graph
+	n := len(owner.unnamed)
+	o, err := owner.construct(c)
+	if err != nil {
+		return nil, err
+	}
+	if err := owner.populateInterfaces(n); err != nil {
+		return nil, err
+	}
+	if err := owner.collectedErrors(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
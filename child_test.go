@@ -0,0 +1,185 @@
+package inject_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeForChildDB struct {
+	Name    string
+	Stopped bool
+}
+
+func (db *TypeForChildDB) Stop(ctx context.Context) error {
+	db.Stopped = true
+	return nil
+}
+
+type TypeForChildService struct {
+	DB     *TypeForChildDB   `inject:""`
+	Tenant string            `inject:"tenant"`
+	All    map[string]string `inject:""`
+}
+
+func newTypeForChildParent(t *testing.T) (*inject.Container, *TypeForChildDB) {
+	parent := inject.NewContainer()
+	db := &TypeForChildDB{Name: "shared"}
+	if err := parent.Provides(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := parent.ProvideWithName("tenant", "default"); err != nil {
+		t.Fatal(err)
+	}
+	if err := parent.ProvideWithName("region", "eu"); err != nil {
+		t.Fatal(err)
+	}
+	if err := parent.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	return parent, db
+}
+
+func TestChildFallsBackToParent(t *testing.T) {
+	parent, db := newTypeForChildParent(t)
+	child := parent.NewChild()
+	var service TypeForChildService
+	if err := child.Provides(&service); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if service.DB != db {
+		t.Fatal("expected the parent db")
+	}
+	if service.Tenant != "default" {
+		t.Fatalf("expected the parent tenant but got %s", service.Tenant)
+	}
+	if service.All["region"] != "eu" || service.All["tenant"] != "default" {
+		t.Fatalf("expected the parent names in the map but got %v", service.All)
+	}
+
+	if _, err := inject.Resolve[*TypeForChildService](parent); err == nil {
+		t.Fatal("expected the parent not to see beans of the child")
+	}
+}
+
+func TestChildShadowsParent(t *testing.T) {
+	parent, db := newTypeForChildParent(t)
+	child := parent.NewChild()
+	own := &TypeForChildDB{Name: "tenant"}
+	var service TypeForChildService
+	if err := child.Provides(own, &service); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.ProvideWithName("tenant", "acme"); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if service.DB != own {
+		t.Fatal("expected the child db to shadow the parent db")
+	}
+	if service.Tenant != "acme" || service.All["tenant"] != "acme" {
+		t.Fatalf("expected the child tenant to shadow the parent tenant but got %s", service.Tenant)
+	}
+	resolved, err := inject.Resolve[*TypeForChildDB](parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != db {
+		t.Fatal("expected the parent to keep its own db")
+	}
+}
+
+func TestChildStopDoesNotStopParentBeans(t *testing.T) {
+	parent, db := newTypeForChildParent(t)
+	child := parent.NewChild()
+	own := &TypeForChildDB{Name: "tenant"}
+	var service struct {
+		Shared *TypeForChildDB `inject:""`
+	}
+	if err := child.Provides(&service); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.ProvideWithName("own", own); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := child.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if !own.Stopped {
+		t.Fatal("expected the child bean to be stopped")
+	}
+	if db.Stopped {
+		t.Fatal("expected the parent bean not to be stopped by the child")
+	}
+}
+
+type TypeForChildCache struct {
+	DB *TypeForChildDB `inject:""`
+}
+
+func TestChildUsesParentConstructor(t *testing.T) {
+	parent := inject.NewContainer()
+	db := &TypeForChildDB{}
+	if err := parent.Provides(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := parent.ProvideConstructor(func() *TypeForChildCache { return &TypeForChildCache{} }); err != nil {
+		t.Fatal(err)
+	}
+	if err := parent.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := inject.Resolve[*TypeForChildCache](parent.NewChild())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := inject.Resolve[*TypeForChildCache](parent.NewChild())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Fatal("expected the parent constructor to be called once for all children")
+	}
+	if first.DB != db {
+		t.Fatal("expected the constructed bean to be populated from the parent")
+	}
+	fromParent, err := inject.Resolve[*TypeForChildCache](parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromParent != first {
+		t.Fatal("expected the constructed bean to be owned by the parent")
+	}
+}
+
+func TestChildMissingDependency(t *testing.T) {
+	parent := inject.NewContainer()
+	child := parent.NewChild()
+	var service TypeForChildService
+	if err := child.Provides(&service); err != nil {
+		t.Fatal(err)
+	}
+	err := child.Populate()
+	var missing *inject.MissingDependencyError
+	if !errors.As(err, &missing) || missing.Name != "tenant" {
+		t.Fatalf("expected a missing tenant but got %v", err)
+	}
+}
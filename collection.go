@@ -0,0 +1,148 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Orderer 由需要控制自己在切片注入中位置的bean实现，值越小越靠前。
+// 未实现Orderer的bean视为0，相同的值按提供顺序排列。
+type Orderer interface {
+	Order() int
+}
+
+// populateSlice 将所有可分配给切片元素类型的非私有未命名对象注入到第i个字段中。
+// 如果标签带有named选项，命名对象也会被收集；如果标签带有限定符，只收集带有这些限定符的对象。
+func (g *Graph) populateSlice(o *Object, i int, tag *tag) error {
+	field := o.reflectValue.Elem().Field(i)
+	fieldType := field.Type()
+	fieldName := o.structInfo().fields[i].name
+
+	// 切片注入不能是私有的，因为我们无法决定要创建哪些元素。
+	if tag.Private {
+		return &InvalidFieldError{
+			Owner:  o.reflectType,
+			Field:  fieldName,
+			format: "found private inject tag on slice field %s in type %s",
+		}
+	}
+
+	// 不要覆盖现有值。
+	if !isNilOrZero(field, fieldType) {
+		return nil
+	}
+
+	elemType := fieldType.Elem()
+
+	// 集合需要所有的bean，所以尚未调用的构造函数也会在这里被调用，
+	// 原型构造函数为这个切片创建一个新的实例。
+	var prototypes []*Object
+	for _, c := range g.constructors {
+		if c.built != nil || (c.Scope == Request && !g.scoped) || !c.out.AssignableTo(elemType) {
+			continue
+		}
+		if err := g.enter(o.reflectType, o.Name, fieldName); err != nil {
+			return err
+		}
+		built, err := g.construct(c)
+		g.leave()
+		if err != nil {
+			return err
+		}
+		if c.Scope == Prototype {
+			prototypes = append(prototypes, built)
+		}
+	}
+
+	found := append(g.assignable(elemType), prototypes...)
+	if len(tag.Qualifiers) > 0 {
+		qualified := found[:0]
+		for _, existing := range found {
+			if existing.matches(tag.Qualifiers) {
+				qualified = append(qualified, existing)
+			}
+		}
+		found = qualified
+	}
+	if tag.Named {
+		for _, existing := range g.namedObjects() {
+			if existing.reflectType.AssignableTo(elemType) {
+				found = append(found, existing)
+			}
+		}
+	}
+	sortByOrder(found)
+
+	// 每个元素都和接口字段一样经过装饰器和拦截器。
+	slice := reflect.MakeSlice(fieldType, 0, len(found))
+	for j, existing := range found {
+		existing, err := g.decorate(elemType, existing)
+		if err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, reflect.ValueOf(existing.Value))
+		g.depend(o, fmt.Sprintf("%s[%d]", fieldName, j), existing)
+	}
+	g.set(o, fieldName, field, slice)
+	if g.Logger != nil {
+		g.Logger.Info("assigned %d existing values to slice field %s in %v", len(found), fieldName, o)
+	}
+	return nil
+}
+
+// sortByOrder 按Orderer给出的值排序，相同的值按提供顺序排列。
+func sortByOrder(objects []*Object) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		oi, oj := orderOf(objects[i]), orderOf(objects[j])
+		if oi != oj {
+			return oi < oj
+		}
+		return objects[i].seq < objects[j].seq
+	})
+}
+
+func orderOf(o *Object) int {
+	if orderer, ok := o.Value.(Orderer); ok {
+		return orderer.Order()
+	}
+	return 0
+}
+
+// populateMap 将所有可分配给Map值类型的命名对象以其名称为键注入到第i个字段中，值同样经过装饰器和拦截器。
+func (g *Graph) populateMap(o *Object, i int) error {
+	field := o.reflectValue.Elem().Field(i)
+	fieldType := field.Type()
+	fieldName := o.structInfo().fields[i].name
+
+	// 不要覆盖现有值。
+	if !isNilOrZero(field, fieldType) {
+		return nil
+	}
+
+	named := g.namedObjects()
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	m := reflect.MakeMapWithSize(fieldType, len(names))
+	for _, name := range names {
+		existing := named[name]
+		if !existing.reflectType.AssignableTo(fieldType.Elem()) {
+			continue
+		}
+		existing, err := g.decorate(fieldType.Elem(), existing)
+		if err != nil {
+			return err
+		}
+		m.SetMapIndex(reflect.ValueOf(name).Convert(fieldType.Key()), reflect.ValueOf(existing.Value))
+		g.depend(o, fmt.Sprintf("%s[%s]", fieldName, name), existing)
+	}
+	g.set(o, fieldName, field, m)
+	if g.Logger != nil {
+		g.Logger.Info("assigned %d named values to map field %s in %v", m.Len(), fieldName, o)
+	}
+	return nil
+}
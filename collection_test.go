@@ -0,0 +1,313 @@
+package inject_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type Handler interface {
+	Handle() string
+}
+
+type TypeForSliceHandlerA struct{}
+
+func (*TypeForSliceHandlerA) Handle() string { return "a" }
+
+type TypeForSliceHandlerB struct{}
+
+func (*TypeForSliceHandlerB) Handle() string { return "b" }
+
+type TypeForSliceOrderedHandler struct {
+	name  string
+	order int
+}
+
+func (h *TypeForSliceOrderedHandler) Handle() string { return h.name }
+
+func (h *TypeForSliceOrderedHandler) Order() int { return h.order }
+
+type TypeForSliceRegistry struct {
+	Handlers []Handler `inject:""`
+}
+
+func handled(handlers []Handler) []string {
+	var actual []string
+	for _, h := range handlers {
+		actual = append(actual, h.Handle())
+	}
+	return actual
+}
+
+func TestInjectSlice(t *testing.T) {
+	var v TypeForSliceRegistry
+	if err := inject.Populate(&TypeForSliceHandlerB{}, &v, &TypeForSliceHandlerA{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"b", "a"}
+	if actual := handled(v.Handlers); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v but got %v", expected, actual)
+	}
+}
+
+type TypeForSliceFirstHandler struct{}
+
+func (*TypeForSliceFirstHandler) Handle() string { return "first" }
+
+func (*TypeForSliceFirstHandler) Order() int { return -10 }
+
+func TestInjectSliceOrder(t *testing.T) {
+	var v TypeForSliceRegistry
+	err := inject.Populate(
+		&TypeForSliceHandlerA{},
+		&TypeForSliceOrderedHandler{name: "last", order: 10},
+		&v,
+		&TypeForSliceFirstHandler{},
+		&TypeForSliceHandlerB{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"first", "a", "b", "last"}
+	if actual := handled(v.Handlers); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v but got %v", expected, actual)
+	}
+}
+
+func TestInjectSliceIgnoresNamed(t *testing.T) {
+	var v TypeForSliceRegistry
+	var g inject.Graph
+	err := g.Provide(
+		&inject.Object{Value: &TypeForSliceHandlerA{}},
+		&inject.Object{Value: &TypeForSliceHandlerB{}, Name: "b"},
+		&inject.Object{Value: &v},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"a"}
+	if actual := handled(v.Handlers); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v but got %v", expected, actual)
+	}
+}
+
+func TestInjectSliceWithNamed(t *testing.T) {
+	var v struct {
+		Handlers []Handler `inject:",named"`
+	}
+	var g inject.Graph
+	err := g.Provide(
+		&inject.Object{Value: &TypeForSliceOrderedHandler{name: "last", order: 10}, Name: "last"},
+		&inject.Object{Value: &TypeForSliceHandlerA{}},
+		&inject.Object{Value: &v},
+		&inject.Object{Value: &TypeForSliceHandlerB{}, Name: "b"},
+		&inject.Object{Value: &TypeForSliceOrderedHandler{name: "first", order: -10}, Name: "first"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"first", "a", "b", "last"}
+	if actual := handled(v.Handlers); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v but got %v", expected, actual)
+	}
+}
+
+func TestInjectSliceEmpty(t *testing.T) {
+	var v TypeForSliceRegistry
+	if err := inject.Populate(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Handlers == nil || len(v.Handlers) != 0 {
+		t.Fatalf("expected an empty slice but got %v", v.Handlers)
+	}
+}
+
+func TestInjectSliceOfStructPointers(t *testing.T) {
+	var v struct {
+		A []*TypeAnswerStruct `inject:""`
+		B *TypeNestedStruct   `inject:""`
+	}
+	if err := inject.Populate(&v); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.A) != 1 || v.A[0] != v.B.A {
+		t.Fatalf("expected the created instance but got %v", v.A)
+	}
+}
+
+func TestInjectSliceDoesNotOverwrite(t *testing.T) {
+	a := &TypeForSliceHandlerA{}
+	v := TypeForSliceRegistry{Handlers: []Handler{a}}
+	if err := inject.Populate(&v, &TypeForSliceHandlerB{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Handlers) != 1 || v.Handlers[0] != a {
+		t.Fatalf("original handlers were lost: %v", v.Handlers)
+	}
+}
+
+func TestInjectSliceConstructors(t *testing.T) {
+	c := inject.NewContainer()
+	var v TypeForSliceRegistry
+	if err := c.Provides(&TypeForSliceHandlerA{}, &v); err != nil {
+		t.Fatal(err)
+	}
+	err := c.ProvideConstructor(func() *TypeForSliceHandlerB { return &TypeForSliceHandlerB{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"a", "b"}
+	if actual := handled(v.Handlers); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v but got %v", expected, actual)
+	}
+}
+
+type TypeWithPrivateSlice struct {
+	Handlers []Handler `inject:"private"`
+}
+
+func TestInjectPrivateSlice(t *testing.T) {
+	var v TypeWithPrivateSlice
+	err := inject.Populate(&v)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "found private inject tag on slice field Handlers in type *inject_test.TypeWithPrivateSlice"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+type TypeWithIntSlice struct {
+	Numbers []int `inject:""`
+}
+
+func TestInjectSliceOfUnsupportedElements(t *testing.T) {
+	var v TypeWithIntSlice
+	err := inject.Populate(&v)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "found inject tag on unsupported field Numbers in type *inject_test.TypeWithIntSlice"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+type TypeWithUnknownTagOption struct {
+	Handlers []Handler `inject:",bogus"`
+}
+
+func TestInjectUnknownTagOption(t *testing.T) {
+	var v TypeWithUnknownTagOption
+	err := inject.Populate(&v)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "unexpected tag format `inject:\",bogus\"` for field Handlers in type *inject_test.TypeWithUnknownTagOption"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+type TypeForMapRouter struct {
+	Routes map[string]Handler `inject:""`
+}
+
+func TestInjectMapByName(t *testing.T) {
+	var g inject.Graph
+	var v TypeForMapRouter
+	a := &TypeForSliceHandlerA{}
+	b := &TypeForSliceHandlerB{}
+	err := g.Provide(
+		&inject.Object{Value: a, Name: "/a"},
+		&inject.Object{Value: b, Name: "/b"},
+		&inject.Object{Value: &TypeAnswerStruct{}, Name: "answer"},
+		&inject.Object{Value: &TypeForSliceHandlerA{}},
+		&inject.Object{Value: &v},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]Handler{"/a": a, "/b": b}
+	if !reflect.DeepEqual(v.Routes, expected) {
+		t.Fatalf("expected %v but got %v", expected, v.Routes)
+	}
+}
+
+type routeName string
+
+func TestInjectMapWithNamedKeyAndValues(t *testing.T) {
+	var g inject.Graph
+	var v struct {
+		Ports map[routeName]int `inject:""`
+	}
+	err := g.Provide(
+		&inject.Object{Value: 80, Name: "http"},
+		&inject.Object{Value: 443, Name: "https"},
+		&inject.Object{Value: "localhost", Name: "host"},
+		&inject.Object{Value: &v},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[routeName]int{"http": 80, "https": 443}
+	if !reflect.DeepEqual(v.Ports, expected) {
+		t.Fatalf("expected %v but got %v", expected, v.Ports)
+	}
+}
+
+func TestInjectMapEmpty(t *testing.T) {
+	var v TypeForMapRouter
+	if err := inject.Populate(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Routes == nil || len(v.Routes) != 0 {
+		t.Fatalf("expected an empty map but got %v", v.Routes)
+	}
+}
+
+func TestInjectMapDoesNotOverwrite(t *testing.T) {
+	var g inject.Graph
+	a := &TypeForSliceHandlerA{}
+	v := TypeForMapRouter{Routes: map[string]Handler{"/": a}}
+	err := g.Provide(
+		&inject.Object{Value: &TypeForSliceHandlerB{}, Name: "/b"},
+		&inject.Object{Value: &v},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Routes) != 1 || v.Routes["/"] != a {
+		t.Fatalf("original routes were lost: %v", v.Routes)
+	}
+}
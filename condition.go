@@ -0,0 +1,162 @@
+package inject
+
+import (
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ProfilesEnv 没有通过SetProfiles指定时，从该环境变量中读取以逗号分隔的激活profile
+const ProfilesEnv = "INJECT_PROFILES"
+
+// Condition 决定条件注册的bean是否被提供
+type Condition func(ctx *ConditionContext) bool
+
+// ConditionContext 条件求值时可以访问的容器状态
+type ConditionContext struct {
+	profiles map[string]bool
+	graph    *Graph
+}
+
+// ProfileActive 返回profile是否被激活
+func (ctx *ConditionContext) ProfileActive(profile string) bool {
+	return ctx.profiles[profile]
+}
+
+// Property 在容器的配置来源中查找键
+func (ctx *ConditionContext) Property(key string) (string, bool) {
+	return ctx.graph.property(key)
+}
+
+// HasBean 返回是否已经提供了可分配给t的未命名bean或构造函数
+func (ctx *ConditionContext) HasBean(t reflect.Type) bool {
+	return len(ctx.graph.assignable(t)) > 0 || ctx.graph.hasConstructor(t)
+}
+
+// Profile 在任意一个profile被激活时成立，以"!"开头的profile在其未被激活时成立。
+func Profile(profiles ...string) Condition {
+	return func(ctx *ConditionContext) bool {
+		for _, p := range profiles {
+			if strings.HasPrefix(p, "!") {
+				if !ctx.ProfileActive(p[1:]) {
+					return true
+				}
+			} else if ctx.ProfileActive(p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ConditionalOnMissingBean 在还没有可分配给T的未命名bean或构造函数时成立。
+// 条件按注册顺序求值，所有无条件的bean都在条件求值之前提供。
+func ConditionalOnMissingBean[T any]() Condition {
+	t := typeOf[T]()
+	return func(ctx *ConditionContext) bool {
+		return !ctx.HasBean(t)
+	}
+}
+
+// ConditionalOnProperty 在配置键存在时成立。给出havingValue时配置值必须与之相等，
+// 否则配置值不能为"false"。
+func ConditionalOnProperty(key string, havingValue ...string) Condition {
+	return func(ctx *ConditionContext) bool {
+		v, ok := ctx.Property(key)
+		if !ok {
+			return false
+		}
+		if len(havingValue) > 0 {
+			return v == havingValue[0]
+		}
+		return !strings.EqualFold(v, "false")
+	}
+}
+
+// conditional 一个等待条件求值的注册
+type conditional struct {
+	condition Condition
+	object    *Object
+	ctor      *Constructor
+}
+
+// ProvideIf 提供一些在条件成立时才会被注册的bean，条件在Populate之前按注册顺序求值。
+func (c *Container) ProvideIf(condition Condition, beans ...interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("provide beans", StateConfiguring); err != nil {
+		return err
+	}
+	for _, bean := range beans {
+		c.conditionals = append(c.conditionals, conditional{condition: condition, object: &Object{Value: bean}})
+	}
+	return nil
+}
+
+// ProvideConstructorIf 提供一个在条件成立时才会被注册的构造函数
+func (c *Container) ProvideConstructorIf(condition Condition, fn interface{}, paramNames ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("provide constructors", StateConfiguring); err != nil {
+		return err
+	}
+	c.conditionals = append(c.conditionals, conditional{
+		condition: condition,
+		ctor:      &Constructor{Func: fn, Params: paramNames},
+	})
+	return nil
+}
+
+// SetProfiles 设置激活的profile，覆盖INJECT_PROFILES环境变量
+func (c *Container) SetProfiles(profiles ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.profiles = append([]string{}, profiles...)
+}
+
+// activeProfiles 返回激活的profile，没有通过SetProfiles设置时读取环境变量。
+func (c *Container) activeProfiles() map[string]bool {
+	profiles := c.profiles
+	if profiles == nil {
+		profiles = strings.Split(os.Getenv(ProfilesEnv), ",")
+	}
+	active := make(map[string]bool, len(profiles))
+	for _, p := range profiles {
+		if p = strings.TrimSpace(p); p != "" {
+			active[p] = true
+		}
+	}
+	return active
+}
+
+// evaluateConditions 按注册顺序对条件求值并提供条件成立的注册，调用方必须持有c.mu。
+func (c *Container) evaluateConditions() error {
+	if len(c.conditionals) == 0 {
+		return nil
+	}
+	ctx := &ConditionContext{profiles: c.activeProfiles(), graph: &c.graph}
+	pending := c.conditionals
+	c.conditionals = nil
+	for _, p := range pending {
+		if !p.condition(ctx) {
+			if c.graph.Logger != nil {
+				if p.ctor != nil {
+					c.graph.Logger.Info("skipped conditional constructor %T", p.ctor.Func)
+				} else {
+					c.graph.Logger.Info("skipped conditional %T", p.object.Value)
+				}
+			}
+			continue
+		}
+		var err error
+		if p.ctor != nil {
+			err = c.graph.ProvideConstructor(p.ctor)
+		} else {
+			err = c.graph.Provide(p.object)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
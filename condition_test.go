@@ -0,0 +1,122 @@
+package inject_test
+
+import (
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeForConditionRepo interface {
+	Kind() string
+}
+
+type TypeForConditionMemoryRepo struct{}
+
+func (*TypeForConditionMemoryRepo) Kind() string { return "memory" }
+
+type TypeForConditionPostgresRepo struct{}
+
+func (*TypeForConditionPostgresRepo) Kind() string { return "postgres" }
+
+type TypeForConditionCache struct{}
+
+type TypeForConditionService struct {
+	Repo  TypeForConditionRepo   `inject:""`
+	Cache *TypeForConditionCache `inject:",optional"`
+}
+
+func newTypeForConditionContainer(t *testing.T) (*inject.Container, *TypeForConditionService) {
+	c := inject.NewContainer()
+	var service TypeForConditionService
+	if err := c.Provides(&service); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideIf(inject.Profile("dev", "test"), &TypeForConditionMemoryRepo{}); err != nil {
+		t.Fatal(err)
+	}
+	err := c.ProvideConstructorIf(inject.Profile("prod"), func() *TypeForConditionPostgresRepo {
+		return &TypeForConditionPostgresRepo{}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideIf(inject.ConditionalOnMissingBean[TypeForConditionRepo](), &TypeForConditionMemoryRepo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideIf(inject.ConditionalOnProperty("cache.enabled"), &TypeForConditionCache{}); err != nil {
+		t.Fatal(err)
+	}
+	return c, &service
+}
+
+func TestConditionProfiles(t *testing.T) {
+	c, service := newTypeForConditionContainer(t)
+	c.SetProfiles("prod")
+	c.AddPropertySource(inject.MapSource{"cache.enabled": "true"})
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if service.Repo.Kind() != "postgres" {
+		t.Fatalf("expected the postgres repo in prod but got %s", service.Repo.Kind())
+	}
+	if service.Cache == nil {
+		t.Fatal("expected the cache to be enabled by its property")
+	}
+}
+
+func TestConditionProfilesFromEnv(t *testing.T) {
+	t.Setenv(inject.ProfilesEnv, "test, other")
+	c, service := newTypeForConditionContainer(t)
+	c.AddPropertySource(inject.MapSource{"cache.enabled": "false"})
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if service.Repo.Kind() != "memory" {
+		t.Fatalf("expected the memory repo in test but got %s", service.Repo.Kind())
+	}
+	if service.Cache != nil {
+		t.Fatal("expected the cache to be disabled by its property")
+	}
+}
+
+func TestConditionalOnMissingBean(t *testing.T) {
+	t.Setenv(inject.ProfilesEnv, "")
+	c, service := newTypeForConditionContainer(t)
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if service.Repo.Kind() != "memory" {
+		t.Fatalf("expected the fallback memory repo but got %s", service.Repo.Kind())
+	}
+	if service.Cache != nil {
+		t.Fatal("expected the cache to be disabled without its property")
+	}
+}
+
+func TestConditionNegatedProfileAndValue(t *testing.T) {
+	c := inject.NewContainer()
+	c.SetProfiles("dev")
+	c.AddPropertySource(inject.MapSource{"repo.kind": "postgres"})
+	var service TypeForConditionService
+	if err := c.Provides(&service); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideIf(inject.Profile("!dev"), &TypeForConditionCache{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideIf(inject.ConditionalOnProperty("repo.kind", "memory"), &TypeForConditionMemoryRepo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideIf(inject.ConditionalOnProperty("repo.kind", "postgres"), &TypeForConditionPostgresRepo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if service.Cache != nil {
+		t.Fatal("expected the cache to be skipped in dev")
+	}
+	if service.Repo.Kind() != "postgres" {
+		t.Fatalf("expected the postgres repo but got %s", service.Repo.Kind())
+	}
+}
@@ -0,0 +1,267 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Constructor 通过构造函数延迟创建的bean。
+// 构造函数只会在其结果第一次被需要时调用一次，参数从依赖图中注入；
+// Prototype作用域的构造函数在每次被需要时都会调用，Request作用域的构造函数在每个请求作用域中调用一次。
+type Constructor struct {
+	Func      interface{} // 形如 func(A, B, ...) T 或 func(A, B, ...) (T, error)
+	Params    []string    // 可选的，按参数位置指定注入的对象名称，空字符串表示按类型注入
+	Scope     Scope       // 可选的，默认为Singleton
+	fn        reflect.Value
+	out       reflect.Type // 构造函数的返回类型T
+	graph     *Graph       // 提供该构造函数的依赖图
+	module    *Module      // 提供该构造函数的模块
+	decorator bool         // 是否是装饰器用于注入参数的构造函数
+	built     *Object      // 构造函数调用后得到的对象
+}
+
+func (c *Constructor) String() string {
+	if c.decorator {
+		return fmt.Sprintf("decorator %s", c.fn.Type())
+	}
+	return fmt.Sprintf("constructor %s", c.fn.Type())
+}
+
+// ProvideConstructor 提供一些构造函数，其结果T必须是结构体指针或接口。
+// 结果为接口时构造函数可以返回任何实现，非结构体指针的实现不会被注入字段。
+func (g *Graph) ProvideConstructor(constructors ...*Constructor) error {
+	for _, c := range constructors {
+		c.fn = reflect.ValueOf(c.Func)
+		if c.fn.Kind() != reflect.Func {
+			return fmt.Errorf("expected constructor to be a function but got type %T", c.Func)
+		}
+
+		fnType := c.fn.Type()
+		if fnType.IsVariadic() {
+			return fmt.Errorf("variadic constructor %s is not supported", fnType)
+		}
+		if fnType.NumOut() == 0 || fnType.NumOut() > 2 ||
+			(fnType.NumOut() == 2 && fnType.Out(1) != errorType) {
+			return fmt.Errorf("expected constructor %s to return T or (T, error)", fnType)
+		}
+		if len(c.Params) > fnType.NumIn() {
+			return fmt.Errorf(
+				"constructor %s has %d parameters but %d parameter names were given",
+				fnType,
+				fnType.NumIn(),
+				len(c.Params),
+			)
+		}
+
+		c.out = fnType.Out(0)
+		if !isStructPtr(c.out) && c.out.Kind() != reflect.Interface {
+			return fmt.Errorf(
+				"expected constructor %s to return a pointer to a struct or an interface",
+				fnType,
+			)
+		}
+
+		if g.unnamedType[c.out] {
+			return &DuplicateProvideError{
+				Type:         c.out,
+				Existing:     g.firstAssignable(c.out),
+				Constructors: []*Constructor{c},
+			}
+		}
+		for _, existing := range g.constructors {
+			if existing.out == c.out {
+				return &DuplicateProvideError{
+					Type:         c.out,
+					Constructors: []*Constructor{existing, c},
+				}
+			}
+		}
+		c.graph = g
+		g.constructors = append(g.constructors, c)
+
+		if g.Logger != nil {
+			g.Logger.Info("provided %v", c)
+		}
+	}
+	return nil
+}
+
+// findConstructor 查找唯一一个尚未调用且结果可分配给t的构造函数。
+func (g *Graph) findConstructor(t reflect.Type) (*Constructor, error) {
+	var found []*Constructor
+	for _, c := range g.constructors {
+		if c.built == nil && c.out.AssignableTo(t) {
+			found = append(found, c)
+		}
+	}
+	switch len(found) {
+	case 0:
+		if g.inherits(t) {
+			return g.parent.findConstructor(t)
+		}
+		return nil, nil
+	case 1:
+		return found[0], nil
+	}
+	return nil, &AmbiguousDependencyError{Type: t, Constructors: found}
+}
+
+// construct 调用构造函数并将其结果作为新创建的对象提供给依赖图。
+func (g *Graph) construct(c *Constructor) (*Object, error) {
+	if c.built != nil {
+		return c.built, nil
+	}
+	switch {
+	case c.Scope == Singleton && c.graph != g:
+		return g.constructInOwner(c)
+	case c.Scope == Request && !g.scoped:
+		if len(g.path) == 0 {
+			return nil, fmt.Errorf("request scoped type %s cannot be used outside of a request scope", c.out)
+		}
+		return nil, fmt.Errorf(
+			"request scoped type %s required by %s cannot be used outside of a request scope",
+			c.out,
+			g.pathString(),
+		)
+	}
+	if g.hidden > 0 {
+		defer g.view(c.module)()
+	}
+	fnType := c.fn.Type()
+	args := make([]reflect.Value, fnType.NumIn())
+	deps := make([]*Object, fnType.NumIn())
+	for i := range args {
+		var name string
+		if i < len(c.Params) {
+			name = c.Params[i]
+		}
+		if err := g.enter(c.out, "", fmt.Sprintf("arg%d", i)); err != nil {
+			return nil, err
+		}
+		dep, err := g.constructorArg(c, i, name)
+		g.leave()
+		if err != nil {
+			return nil, err
+		}
+		args[i] = reflect.ValueOf(dep.Value)
+		deps[i] = dep
+	}
+
+	out := c.fn.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		err := out[1].Interface().(error)
+		if len(g.path) == 0 {
+			return nil, fmt.Errorf("%v failed: %w", c, err)
+		}
+		return nil, fmt.Errorf("%v required by %s failed: %w", c, g.pathString(), err)
+	}
+	if out[0].IsNil() {
+		return nil, fmt.Errorf("%v returned nil", c)
+	}
+
+	// 原型实例是私有的，不会被其他注入点复用。
+	o := &Object{
+		Value:   out[0].Interface(),
+		Scope:   c.Scope,
+		private: c.Scope == Prototype,
+		created: true,
+		module:  c.module,
+	}
+	if err := g.Provide(o); err != nil {
+		return nil, err
+	}
+	for i, dep := range deps {
+		o.addDep(fmt.Sprintf("arg%d", i), dep)
+	}
+	if c.Scope == Singleton {
+		c.built = o
+	}
+
+	if err := g.populateExplicit(o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// constructorArg 为构造函数的第i个参数找到要注入的对象。
+func (g *Graph) constructorArg(c *Constructor, i int, name string) (*Object, error) {
+	argType := c.fn.Type().In(i)
+	if name != "" {
+		existing := g.lookupNamed(name)
+		if existing == nil {
+			return nil, &MissingDependencyError{
+				Owner:       c.out,
+				Field:       fmt.Sprintf("arg%d", i),
+				Constructor: c,
+				Type:        argType,
+				Name:        name,
+			}
+		}
+		if !existing.reflectType.AssignableTo(argType) {
+			return nil, fmt.Errorf(
+				"object named %s of type %s is not assignable to argument %d (%s) of %v",
+				name,
+				existing.reflectType,
+				i,
+				argType,
+				c,
+			)
+		}
+		return existing, nil
+	}
+
+	if argType.Kind() == reflect.Interface {
+		o, err := g.resolve(argType)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d of %v: %w", i, c, err)
+		}
+		return o, nil
+	}
+
+	if !isStructPtr(argType) {
+		return nil, fmt.Errorf(
+			"argument %d (%s) of %v must be named or a pointer to a struct",
+			i,
+			argType,
+			c,
+		)
+	}
+
+	return g.lookupOrCreate(argType)
+}
+
+// lookupOrCreate 返回可分配给结构体指针类型t的对象，依次查找现有对象和构造函数，都没有时创建一个新的对象。
+func (g *Graph) lookupOrCreate(t reflect.Type) (*Object, error) {
+	existing, ambiguous := pick(t, g.assignable(t))
+	if ambiguous != nil {
+		return nil, &AmbiguousDependencyError{Type: t, Candidates: ambiguous}
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	dep, err := g.findConstructor(t)
+	if err != nil {
+		return nil, err
+	}
+	if dep != nil {
+		return g.construct(dep)
+	}
+	if g.scoped {
+		return g.createInOwner(t)
+	}
+
+	o := &Object{
+		Value:   reflect.New(t.Elem()).Interface(),
+		created: true,
+	}
+	if err := g.Provide(o); err != nil {
+		return nil, err
+	}
+	if err := g.populateExplicit(o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
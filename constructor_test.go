@@ -0,0 +1,279 @@
+package inject_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeForConstructorConfig struct {
+	DSN string
+}
+
+type TypeForConstructorDB struct {
+	DSN string
+}
+
+func NewTypeForConstructorDB(config *TypeForConstructorConfig) (*TypeForConstructorDB, error) {
+	if config.DSN == "" {
+		return nil, errors.New("empty dsn")
+	}
+	return &TypeForConstructorDB{DSN: config.DSN}, nil
+}
+
+type TypeForConstructorRepo struct {
+	DB     *TypeForConstructorDB
+	Answer *TypeAnswerStruct `inject:""`
+}
+
+func NewTypeForConstructorRepo(db *TypeForConstructorDB) *TypeForConstructorRepo {
+	return &TypeForConstructorRepo{DB: db}
+}
+
+type TypeForConstructorApp struct {
+	Repo *TypeForConstructorRepo `inject:""`
+}
+
+func TestConstructor(t *testing.T) {
+	c := inject.NewContainer()
+	config := &TypeForConstructorConfig{DSN: "postgres://localhost"}
+	var app TypeForConstructorApp
+	if err := c.Provides(config, &app); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideConstructor(NewTypeForConstructorDB); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideConstructor(NewTypeForConstructorRepo); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.Repo == nil {
+		t.Fatal("app.Repo is nil")
+	}
+	if app.Repo.DB == nil || app.Repo.DB.DSN != config.DSN {
+		t.Fatal("app.Repo.DB was not constructed from config")
+	}
+	if app.Repo.Answer == nil {
+		t.Fatal("fields of the constructed object were not injected")
+	}
+
+	db, err := inject.Resolve[*TypeForConstructorDB](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db != app.Repo.DB {
+		t.Fatal("constructor was called more than once")
+	}
+}
+
+func TestConstructorIsLazy(t *testing.T) {
+	c := inject.NewContainer()
+	var called int
+	err := c.ProvideConstructor(func() *TypeAnswerStruct {
+		called++
+		return &TypeAnswerStruct{answer: 42}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if called != 0 {
+		t.Fatal("constructor was called before it was needed")
+	}
+
+	a, err := inject.Resolve[Answerable](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Answer() != 42 {
+		t.Fatalf("expected 42 but got %d", a.Answer())
+	}
+	if _, err := inject.Resolve[*TypeAnswerStruct](c); err != nil {
+		t.Fatal(err)
+	}
+	if called != 1 {
+		t.Fatalf("expected constructor to be called once but was called %d times", called)
+	}
+}
+
+func TestConstructorForInterface(t *testing.T) {
+	c := inject.NewContainer()
+	var v TypeInjectInterfaceMissing
+	if err := c.Provides(&v); err != nil {
+		t.Fatal(err)
+	}
+	err := c.ProvideConstructor(func() Answerable {
+		return &TypeAnswerStruct{answer: 7}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.Answerable == nil || v.Answerable.Answer() != 7 {
+		t.Fatal("interface field was not constructed")
+	}
+}
+
+type TypeForConstructorAnswerFunc func() int
+
+func (f TypeForConstructorAnswerFunc) Answer() int { return f() }
+
+func TestConstructorForInterfaceWithFunc(t *testing.T) {
+	c := inject.NewContainer()
+	var v TypeInjectInterfaceMissing
+	if err := c.Provides(&v); err != nil {
+		t.Fatal(err)
+	}
+	err := c.ProvideConstructor(func(config *TypeForConstructorConfig) Answerable {
+		return TypeForConstructorAnswerFunc(func() int { return 42 })
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.Answerable == nil || v.Answerable.Answer() != 42 {
+		t.Fatal("interface field was not constructed")
+	}
+
+	resolved, err := inject.Resolve[Answerable](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Answer() != 42 {
+		t.Fatal("expected Resolve to return the constructed function")
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConstructorNamedParam(t *testing.T) {
+	c := inject.NewContainer()
+	if err := c.ProvideWithName("dsn", "mysql://localhost"); err != nil {
+		t.Fatal(err)
+	}
+	err := c.ProvideConstructor(func(dsn string) *TypeForConstructorDB {
+		return &TypeForConstructorDB{DSN: dsn}
+	}, "dsn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := inject.Resolve[*TypeForConstructorDB](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.DSN != "mysql://localhost" {
+		t.Fatalf("unexpected dsn %s", db.DSN)
+	}
+}
+
+func TestConstructorErrorPath(t *testing.T) {
+	c := inject.NewContainer()
+	var app TypeForConstructorApp
+	if err := c.Provides(&TypeForConstructorConfig{}, &app); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideConstructor(NewTypeForConstructorDB); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideConstructor(NewTypeForConstructorRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	err := c.Populate()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "constructor func(*inject_test.TypeForConstructorConfig) (*inject_test.TypeForConstructorDB, error) required by *inject_test.TypeForConstructorApp.Repo -> *inject_test.TypeForConstructorRepo.arg0 failed: empty dsn"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+func TestConstructorCycle(t *testing.T) {
+	type A struct{}
+	type B struct{}
+	c := inject.NewContainer()
+	if err := c.ProvideConstructor(func(*B) *A { return &A{} }); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideConstructor(func(*A) *B { return &B{} }); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := inject.Resolve[*A](c)
+	expectCycle(t, err, "dependency cycle detected: "+
+		"*inject_test.A.arg0 -> *inject_test.B.arg0")
+}
+
+func TestConstructorInvalid(t *testing.T) {
+	cases := []struct {
+		Func    interface{}
+		Message string
+	}{
+		{
+			Func:    42,
+			Message: "expected constructor to be a function but got type int",
+		},
+		{
+			Func:    func() {},
+			Message: "expected constructor func() to return T or (T, error)",
+		},
+		{
+			Func:    func() (*TypeAnswerStruct, int) { return nil, 0 },
+			Message: "expected constructor func() (*inject_test.TypeAnswerStruct, int) to return T or (T, error)",
+		},
+		{
+			Func:    func() int { return 0 },
+			Message: "expected constructor func() int to return a pointer to a struct or an interface",
+		},
+		{
+			Func:    func(...int) *TypeAnswerStruct { return nil },
+			Message: "variadic constructor func(...int) *inject_test.TypeAnswerStruct is not supported",
+		},
+	}
+
+	for _, e := range cases {
+		var g inject.Graph
+		err := g.ProvideConstructor(&inject.Constructor{Func: e.Func})
+		if err == nil {
+			t.Fatalf("expected error for %T", e.Func)
+		}
+		if err.Error() != e.Message {
+			t.Fatalf("expected:\n%s\nactual:\n%s", e.Message, err.Error())
+		}
+	}
+}
+
+func TestConstructorConflictsWithInstance(t *testing.T) {
+	c := inject.NewContainer()
+	if err := c.Provides(&TypeAnswerStruct{}); err != nil {
+		t.Fatal(err)
+	}
+	err := c.ProvideConstructor(func() *TypeAnswerStruct { return &TypeAnswerStruct{} })
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "provided an unnamed instance and a constructor of type *inject_test.TypeAnswerStruct"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
@@ -0,0 +1,163 @@
+package inject_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+func expectError(t *testing.T, err error, msg string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("expected error %q", msg)
+	}
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+func TestContainerState(t *testing.T) {
+	ctx := context.Background()
+	c := inject.NewContainer()
+	if c.State() != inject.StateConfiguring {
+		t.Fatalf("unexpected state %s", c.State())
+	}
+	expectError(t, c.Start(ctx), "cannot start when the container is configuring")
+	if err := c.Provides(&TypeNestedStruct{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if c.State() != inject.StatePopulated {
+		t.Fatalf("unexpected state %s", c.State())
+	}
+
+	expectError(t, c.Provides(&TypeAnswerStruct{}), "cannot provide beans when the container is populated")
+	expectError(t, c.ProvideWithName("foo", 42), "cannot provide beans when the container is populated")
+	expectError(t, c.Populate(), "cannot populate when the container is populated")
+	expectError(t, c.Stop(ctx), "cannot stop when the container is populated")
+
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if c.State() != inject.StateStarted {
+		t.Fatalf("unexpected state %s", c.State())
+	}
+	expectError(t, c.Start(ctx), "cannot start when the container is started")
+
+	if err := c.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if c.State() != inject.StateStopped {
+		t.Fatalf("unexpected state %s", c.State())
+	}
+	if err := c.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContainerConcurrentResolve(t *testing.T) {
+	c := inject.NewContainer()
+	var called int
+	err := c.ProvideConstructor(func() *TypeAnswerStruct {
+		called++
+		return &TypeAnswerStruct{answer: 42}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	results := make([]Answerable, 20)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			a, err := inject.Resolve[Answerable](c)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results[i] = a
+		}(i)
+	}
+	wg.Wait()
+
+	if called != 1 {
+		t.Fatalf("expected constructor to be called once but was called %d times", called)
+	}
+	for _, a := range results {
+		if a != results[0] {
+			t.Fatal("resolved different instances")
+		}
+	}
+}
+
+func TestContainerConcurrentProvide(t *testing.T) {
+	c := inject.NewContainer()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := c.ProvideWithName(fmt.Sprintf("answer%d", i), &TypeAnswerStruct{answer: i}); err != nil {
+				t.Error(err)
+			}
+			if _, err := inject.ResolveNamed[*TypeAnswerStruct](c, fmt.Sprintf("answer%d", i)); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		a, err := inject.ResolveNamed[*TypeAnswerStruct](c, fmt.Sprintf("answer%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Answer() != i {
+			t.Fatalf("expected %d but got %d", i, a.Answer())
+		}
+	}
+}
+
+type TypeForContainerResolveOnStart struct {
+	container *inject.Container
+	resolved  Answerable
+}
+
+func (s *TypeForContainerResolveOnStart) Start(ctx context.Context) error {
+	a, err := inject.Resolve[Answerable](s.container)
+	s.resolved = a
+	return err
+}
+
+func TestContainerResolveOnStart(t *testing.T) {
+	c := inject.NewContainer()
+	s := &TypeForContainerResolveOnStart{container: c}
+	if err := c.Provides(s, &TypeAnswerStruct{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s.resolved == nil {
+		t.Fatal("failed to resolve during start")
+	}
+}
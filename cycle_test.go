@@ -0,0 +1,119 @@
+package inject_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeCycleA struct {
+	B *TypeCycleB `inject:"private"`
+}
+
+type TypeCycleB struct {
+	C *TypeCycleC `inject:"private"`
+}
+
+type TypeCycleC struct {
+	A *TypeCycleA `inject:"private"`
+}
+
+func expectCycle(t *testing.T, err error, msg string) {
+	t.Helper()
+	var cycle *inject.CycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("expected a cycle error but got %v", err)
+	}
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+func TestCyclePrivate(t *testing.T) {
+	err := inject.Populate(&TypeCycleA{})
+	expectCycle(t, err, "dependency cycle detected: "+
+		"*inject_test.TypeCycleA.B -> *inject_test.TypeCycleB.C -> *inject_test.TypeCycleC.A")
+}
+
+type TypeCycleSelf struct {
+	Self *TypeCycleSelf `inject:"private"`
+}
+
+func TestCyclePrivateSelf(t *testing.T) {
+	err := inject.Populate(&TypeCycleSelf{})
+	expectCycle(t, err, "dependency cycle detected: *inject_test.TypeCycleSelf.Self")
+}
+
+func TestCycleNamed(t *testing.T) {
+	var g inject.Graph
+	if err := g.Provide(&inject.Object{Value: &TypeCycleA{}, Name: "root"}); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Populate()
+	expectCycle(t, err, "dependency cycle detected: "+
+		"*inject_test.TypeCycleB.C -> *inject_test.TypeCycleC.A -> *inject_test.TypeCycleA.B")
+}
+
+func TestCycleNamedSelf(t *testing.T) {
+	var g inject.Graph
+	if err := g.Provide(&inject.Object{Value: &TypeCycleSelf{}, Name: "root"}); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Populate()
+	expectCycle(t, err, "dependency cycle detected: *inject_test.TypeCycleSelf.Self")
+}
+
+func TestCycleUnderDeepInjection(t *testing.T) {
+	var v struct {
+		A *TypeCycleA `inject:""`
+	}
+	v.A = &TypeCycleA{}
+	err := inject.Populate(&v)
+	expectCycle(t, err, "dependency cycle detected: "+
+		"*inject_test.TypeCycleA.B -> *inject_test.TypeCycleB.C -> *inject_test.TypeCycleC.A")
+}
+
+type TypeNoCycleA struct {
+	X *TypeNoCycleX `inject:""`
+}
+
+type TypeNoCycleX struct {
+	A *TypeNoCycleA `inject:"private"`
+}
+
+func TestPrivateRecursionThatTerminates(t *testing.T) {
+	a := &TypeNoCycleA{}
+	if err := inject.Populate(a); err != nil {
+		t.Fatal(err)
+	}
+	if a.X == nil || a.X.A == nil || a.X.A == a {
+		t.Fatal("expected a private A to be created for X")
+	}
+	if a.X.A.X != a.X {
+		t.Fatal("expected the private A to share X")
+	}
+}
+
+type TypeDeepInjectNode struct {
+	Next *TypeDeepInjectNode `inject:""`
+}
+
+func TestDeepInjectChainOfSameType(t *testing.T) {
+	n3 := &TypeDeepInjectNode{}
+	n2 := &TypeDeepInjectNode{Next: n3}
+	n1 := &TypeDeepInjectNode{Next: n2}
+	var v struct {
+		Head *TypeDeepInjectNode `inject:""`
+	}
+	v.Head = n1
+	if err := inject.Populate(&v); err != nil {
+		t.Fatal(err)
+	}
+	if n1.Next != n2 || n2.Next != n3 {
+		t.Fatal("existing values were overwritten")
+	}
+	if n3.Next == nil {
+		t.Fatal("n3.Next was not injected")
+	}
+}
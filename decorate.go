@@ -0,0 +1,203 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Decorator 在注入之前包装某个接口类型T的bean，例如加上缓存、指标或重试。
+// 接口字段注入和Resolve得到的都是包装后的值，多个装饰器按注册顺序依次包装。
+type Decorator struct {
+	Func   interface{} // 形如 func(T, A, B, ...) T 或 func(T, A, B, ...) (T, error)
+	Params []string    // 可选的，按位置为第一个参数之后的参数指定注入的对象名称
+	args   *Constructor
+}
+
+func (d *Decorator) String() string {
+	return d.args.String()
+}
+
+// Decorate 为接口类型T注册一个装饰器，形如 func(T, A, B, ...) T 或 func(T, A, B, ...) (T, error)。
+// T的接口字段注入和Resolve得到的都是包装后的值，paramNames可选地按位置为第一个参数之后的参数指定注入的对象名称。
+func Decorate[T any](c *Container, fn interface{}, paramNames ...string) error {
+	t := typeOf[T]()
+	if fnType := reflect.TypeOf(fn); fnType == nil || fnType.Kind() != reflect.Func ||
+		fnType.NumIn() == 0 || fnType.In(0) != t {
+		return fmt.Errorf("expected decorator %T to take %s as its first argument", fn, t)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("provide decorators", StateConfiguring); err != nil {
+		return err
+	}
+	return c.graph.Decorate(&Decorator{Func: fn, Params: paramNames})
+}
+
+// decoration 被装饰的接口类型及其底层对象
+type decoration struct {
+	typ    reflect.Type
+	object *Object
+}
+
+// Decorate 注册一些装饰器，装饰的接口类型T由第一个参数决定。
+func (g *Graph) Decorate(decorators ...*Decorator) error {
+	for _, d := range decorators {
+		fn := reflect.ValueOf(d.Func)
+		if fn.Kind() != reflect.Func {
+			return fmt.Errorf("expected decorator to be a function but got type %T", d.Func)
+		}
+
+		fnType := fn.Type()
+		if fnType.IsVariadic() {
+			return fmt.Errorf("variadic decorator %s is not supported", fnType)
+		}
+		if fnType.NumIn() == 0 || fnType.In(0).Kind() != reflect.Interface {
+			return fmt.Errorf("expected decorator %s to take an interface as its first argument", fnType)
+		}
+		t := fnType.In(0)
+		if fnType.NumOut() == 0 || fnType.NumOut() > 2 || fnType.Out(0) != t ||
+			(fnType.NumOut() == 2 && fnType.Out(1) != errorType) {
+			return fmt.Errorf("expected decorator %s to return %s or (%s, error)", fnType, t, t)
+		}
+		if len(d.Params) > fnType.NumIn()-1 {
+			return fmt.Errorf(
+				"decorator %s has %d parameters after the decorated value but %d parameter names were given",
+				fnType,
+				fnType.NumIn()-1,
+				len(d.Params),
+			)
+		}
+
+		d.args = &Constructor{Func: d.Func, fn: fn, out: t, graph: g, decorator: true}
+		if g.decorators == nil {
+			g.decorators = make(map[reflect.Type][]*Decorator)
+		}
+		g.decorators[t] = append(g.decorators[t], d)
+
+		if g.Logger != nil {
+			g.Logger.Info("provided %v", d)
+		}
+	}
+	return nil
+}
+
+// decoratorsFor 返回类型t的所有装饰器，父级的装饰器在前。
+func (g *Graph) decoratorsFor(t reflect.Type) []*Decorator {
+	if g.parent == nil {
+		return g.decorators[t]
+	}
+	inherited := g.parent.decoratorsFor(t)
+	if len(inherited) == 0 {
+		return g.decorators[t]
+	}
+	return append(append([]*Decorator(nil), inherited...), g.decorators[t]...)
+}
+
+// decorate 返回注入到类型为t的位置时o经过所有装饰器包装，并在有匹配的拦截器时再由代理包装后的对象，
+// 两者都没有时返回o本身。每个对象只会被包装一次，装饰之前o的接口字段会先完成注入。
+func (g *Graph) decorate(t reflect.Type, o *Object) (*Object, error) {
+	decorators := g.decoratorsFor(t)
+	interceptors := g.interceptorsFor(t, o)
+	if len(decorators) == 0 && len(interceptors) == 0 {
+		return o, nil
+	}
+	key := decoration{typ: t, object: o}
+	if decorated := g.decorated[key]; decorated != nil {
+		return decorated, nil
+	}
+
+	if len(decorators) > 0 && !o.Complete && g.owns(o) {
+		if err := g.enter(o.reflectType, o.Name, "decorated"); err != nil {
+			return nil, err
+		}
+		err := g.populateUnnamedInterface(o)
+		g.leave()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	current := o
+	for _, d := range decorators {
+		next, err := g.applyDecorator(d, current)
+		if err != nil {
+			return nil, err
+		}
+		current = next
+	}
+	if len(interceptors) > 0 {
+		proxied, err := g.proxy(t, current, interceptors)
+		if err != nil {
+			return nil, err
+		}
+		current = proxied
+	}
+
+	if g.decorated == nil {
+		g.decorated = make(map[decoration]*Object)
+	}
+	g.decorated[key] = current
+	if g.Logger != nil {
+		g.Logger.Info("decorated %v as %s", o, t)
+	}
+	return current, nil
+}
+
+// unwrap 返回装饰器和代理包装的原始对象，o没有被包装时返回o本身。
+func unwrap(o *Object) *Object {
+	for o.decorated {
+		if inner := o.Fields["arg0"]; inner != nil {
+			o = inner
+		} else {
+			o = o.Fields["target"]
+		}
+	}
+	return o
+}
+
+// applyDecorator 用装饰器d包装o，返回的对象不加入依赖图，只记录它对o和其他参数的依赖。
+func (g *Graph) applyDecorator(d *Decorator, o *Object) (*Object, error) {
+	c := d.args
+	fnType := c.fn.Type()
+	args := make([]reflect.Value, fnType.NumIn())
+	deps := make([]*Object, fnType.NumIn())
+	args[0] = reflect.ValueOf(o.Value).Convert(c.out)
+	deps[0] = o
+	for i := 1; i < len(args); i++ {
+		var name string
+		if i-1 < len(d.Params) {
+			name = d.Params[i-1]
+		}
+		if err := g.enter(c.out, "", fmt.Sprintf("arg%d", i)); err != nil {
+			return nil, err
+		}
+		dep, err := g.constructorArg(c, i, name)
+		g.leave()
+		if err != nil {
+			return nil, err
+		}
+		args[i] = reflect.ValueOf(dep.Value)
+		deps[i] = dep
+	}
+
+	out := c.fn.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return nil, fmt.Errorf("%v failed: %w", d, out[1].Interface().(error))
+	}
+	if out[0].IsNil() {
+		return nil, fmt.Errorf("%v returned nil", d)
+	}
+
+	decorated := &Object{
+		Value:     out[0].Interface(),
+		created:   true,
+		private:   true,
+		decorated: true,
+	}
+	decorated.reflectType = reflect.TypeOf(decorated.Value)
+	decorated.reflectValue = reflect.ValueOf(decorated.Value)
+	for i, dep := range deps {
+		decorated.addDep(fmt.Sprintf("arg%d", i), dep)
+	}
+	return decorated, nil
+}
@@ -0,0 +1,245 @@
+package inject_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeForDecorateRepo interface {
+	Find() string
+}
+
+type TypeForDecorateDB struct {
+	Started bool
+}
+
+func (db *TypeForDecorateDB) Start(ctx context.Context) error {
+	db.Started = true
+	return nil
+}
+
+type TypeForDecoratePostgres struct {
+	DB *TypeForDecorateDB `inject:""`
+}
+
+func (p *TypeForDecoratePostgres) Find() string { return "postgres" }
+
+type TypeForDecorateMetrics struct {
+	Calls int
+}
+
+type TypeForDecorateWrapper struct {
+	name  string
+	inner TypeForDecorateRepo
+}
+
+func (w *TypeForDecorateWrapper) Find() string { return w.name + "(" + w.inner.Find() + ")" }
+
+type TypeForDecorateConsumer struct {
+	Repo TypeForDecorateRepo `inject:""`
+	DB   *TypeForDecorateDB  `inject:""`
+
+	sawStartedDB bool
+}
+
+func (c *TypeForDecorateConsumer) Start(ctx context.Context) error {
+	c.sawStartedDB = c.DB.Started
+	return nil
+}
+
+type TypeForDecorateOther struct {
+	Repo TypeForDecorateRepo `inject:""`
+}
+
+func TestDecorate(t *testing.T) {
+	c := inject.NewContainer()
+	var consumer TypeForDecorateConsumer
+	var other TypeForDecorateOther
+	metrics := &TypeForDecorateMetrics{}
+	if err := c.Provides(&consumer, &other, &TypeForDecoratePostgres{}, metrics); err != nil {
+		t.Fatal(err)
+	}
+	err := inject.Decorate[TypeForDecorateRepo](c, func(r TypeForDecorateRepo, m *TypeForDecorateMetrics) TypeForDecorateRepo {
+		m.Calls++
+		if r.(*TypeForDecoratePostgres).DB == nil {
+			t.Error("expected the underlying repo to be populated before it is decorated")
+		}
+		return &TypeForDecorateWrapper{name: "metrics", inner: r}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = inject.Decorate[TypeForDecorateRepo](c, func(r TypeForDecorateRepo) (TypeForDecorateRepo, error) {
+		return &TypeForDecorateWrapper{name: "cache", inner: r}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := consumer.Repo.Find(); got != "cache(metrics(postgres))" {
+		t.Fatalf("expected decorators in registration order but got %s", got)
+	}
+	if other.Repo != consumer.Repo {
+		t.Fatal("expected the underlying repo to be decorated once")
+	}
+	if metrics.Calls != 1 {
+		t.Fatalf("expected the decorator to be called once but it was called %d times", metrics.Calls)
+	}
+	resolved, err := inject.Resolve[TypeForDecorateRepo](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != consumer.Repo {
+		t.Fatal("expected Resolve to return the decorated repo")
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !consumer.sawStartedDB {
+		t.Fatal("expected the dependencies of the decorated repo to start first")
+	}
+}
+
+func TestDecoratePlanThenPopulate(t *testing.T) {
+	c := inject.NewContainer()
+	var consumer struct {
+		Repo    TypeForDecorateRepo     `inject:""`
+		Metrics *TypeForDecorateMetrics `inject:""`
+	}
+	if err := c.Provides(&consumer, &TypeForDecoratePostgres{}); err != nil {
+		t.Fatal(err)
+	}
+	var wrapped *TypeForDecorateMetrics
+	err := inject.Decorate[TypeForDecorateRepo](c, func(r TypeForDecorateRepo, m *TypeForDecorateMetrics) TypeForDecorateRepo {
+		wrapped = m
+		return &TypeForDecorateWrapper{name: "metrics", inner: r}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Plan(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if consumer.Metrics == nil || consumer.Metrics != wrapped {
+		t.Fatal("expected the decorator and the consumer to share the same metrics after planning")
+	}
+}
+
+func TestDecorateExport(t *testing.T) {
+	var g inject.Graph
+	err := g.Provide(
+		&inject.Object{Value: &TypeForDecorateOther{}},
+		&inject.Object{Value: &TypeForDecoratePostgres{}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = g.Decorate(&inject.Decorator{Func: func(r TypeForDecorateRepo) TypeForDecorateRepo {
+		return &TypeForDecorateWrapper{name: "metrics", inner: r}
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(&g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = `"edges":[{"from":"n1","to":"n2","field":"Repo"},{"from":"n2","to":"n3","field":"DB"}]`
+	if !strings.Contains(string(data), expected) {
+		t.Fatalf("expected:\n%s\nactual:\n%s", expected, string(data))
+	}
+}
+
+func TestDecorateCollections(t *testing.T) {
+	c := inject.NewContainer()
+	var consumer struct {
+		Repo  TypeForDecorateRepo            `inject:""`
+		Repos []TypeForDecorateRepo          `inject:""`
+		Named map[string]TypeForDecorateRepo `inject:""`
+	}
+	if err := c.Provides(&consumer, &TypeForDecoratePostgres{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideWithName("replica", &TypeForDecoratePostgres{}); err != nil {
+		t.Fatal(err)
+	}
+	err := inject.Decorate[TypeForDecorateRepo](c, func(r TypeForDecorateRepo) TypeForDecorateRepo {
+		return &TypeForDecorateWrapper{name: "metrics", inner: r}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(consumer.Repos) != 1 || consumer.Repos[0] != consumer.Repo {
+		t.Fatal("expected the slice to hold the same decorated repo as the field")
+	}
+	if got := consumer.Named["replica"].Find(); got != "metrics(postgres)" {
+		t.Fatalf("expected the map value to be decorated but got %s", got)
+	}
+}
+
+func TestDecorateFailure(t *testing.T) {
+	c := inject.NewContainer()
+	var other TypeForDecorateOther
+	if err := c.Provides(&other, &TypeForDecoratePostgres{}); err != nil {
+		t.Fatal(err)
+	}
+	err := inject.Decorate[TypeForDecorateRepo](c, func(r TypeForDecorateRepo) (TypeForDecorateRepo, error) {
+		return nil, errors.New("boom")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Populate()
+	const msg = "decorator func(inject_test.TypeForDecorateRepo) (inject_test.TypeForDecorateRepo, error) failed: boom"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+}
+
+func TestDecorateInvalid(t *testing.T) {
+	c := inject.NewContainer()
+	cases := []struct {
+		fn  interface{}
+		msg string
+	}{
+		{
+			fn:  func(r *TypeForDecoratePostgres) *TypeForDecoratePostgres { return r },
+			msg: "expected decorator func(*inject_test.TypeForDecoratePostgres) *inject_test.TypeForDecoratePostgres to take inject_test.TypeForDecorateRepo as its first argument",
+		},
+		{
+			fn:  func(r TypeForDecorateRepo) string { return "" },
+			msg: "expected decorator func(inject_test.TypeForDecorateRepo) string to return inject_test.TypeForDecorateRepo or (inject_test.TypeForDecorateRepo, error)",
+		},
+	}
+	for _, tc := range cases {
+		err := inject.Decorate[TypeForDecorateRepo](c, tc.fn)
+		if err == nil || err.Error() != tc.msg {
+			t.Fatalf("expected:\n%s\nactual:\n%v", tc.msg, err)
+		}
+	}
+
+	var g inject.Graph
+	err := g.Decorate(&inject.Decorator{Func: func(r *TypeForDecoratePostgres) *TypeForDecoratePostgres { return r }})
+	if err == nil || !strings.Contains(err.Error(), "to take an interface as its first argument") {
+		t.Fatalf("expected decorators of concrete types to be rejected but got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CycleError 表示依赖解析过程中出现了无法终止的循环依赖，
+// Path为组成循环的依赖路径，例如 *A.B -> *B.C -> *C.A。
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return "dependency cycle detected: " + strings.Join(e.Path, " -> ")
+}
+
+// PopulateErrors 在CollectAllErrors模式下Populate遇到的所有错误，按类型和字段排序。
+// 可以通过errors.Is和errors.As识别其中的每个错误。
+type PopulateErrors []error
+
+func (e PopulateErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e PopulateErrors) Unwrap() []error {
+	return e
+}
+
+// fieldError 某个类型的某个字段上发生的错误
+type fieldError struct {
+	owner reflect.Type
+	field string
+	err   error
+}
+
+// MissingDependencyError 表示找不到注入所需的对象或配置。
+type MissingDependencyError struct {
+	Owner       reflect.Type      // 需要该依赖的类型，直接通过Resolve查找时为nil
+	Field       string            // 需要该依赖的字段，构造函数参数为argN
+	Constructor *Constructor      // 依赖是构造函数参数时不为nil
+	Type        reflect.Type      // 请求的类型
+	Name        string            // 按名称请求时的对象名称
+	Property    string            // 通过value标签请求时的配置键
+	Qualifiers  map[string]string // 通过qualifier选项请求时要求的限定符
+}
+
+func (e *MissingDependencyError) Error() string {
+	var msg string
+	switch {
+	case e.Property != "":
+		msg = "did not find property " + e.Property
+	case e.Name != "":
+		msg = "did not find object named " + e.Name
+	case len(e.Qualifiers) > 0:
+		msg = fmt.Sprintf("found no assignable value for type %s with qualifiers %s", e.Type, formatQualifiers(e.Qualifiers))
+	case e.Owner == nil:
+		return fmt.Sprintf("found no assignable value for type %s", e.Type)
+	default:
+		return fmt.Sprintf("found no assignable value for field %s in type %s", e.Field, e.Owner)
+	}
+
+	switch {
+	case e.Constructor != nil:
+		msg += fmt.Sprintf(" required by argument %s of %v", strings.TrimPrefix(e.Field, "arg"), e.Constructor)
+	case e.Owner != nil:
+		msg += fmt.Sprintf(" required by field %s in type %s", e.Field, e.Owner)
+	}
+	return msg
+}
+
+// AmbiguousDependencyError 表示有多个对象或构造函数可以满足同一个依赖。
+type AmbiguousDependencyError struct {
+	Owner        reflect.Type   // 需要该依赖的类型，直接通过Resolve查找时为nil
+	Field        string         // 需要该依赖的字段
+	Type         reflect.Type   // 请求的类型
+	Candidates   []*Object      // 所有可分配的对象
+	Constructors []*Constructor // 所有可分配的构造函数
+}
+
+func (e *AmbiguousDependencyError) Error() string {
+	if len(e.Constructors) > 1 {
+		return fmt.Sprintf(
+			"found two constructors assignable to type %s: %v and %v",
+			e.Type,
+			e.Constructors[0],
+			e.Constructors[1],
+		)
+	}
+
+	target := fmt.Sprintf("type %s", e.Type)
+	if e.Owner != nil {
+		target = fmt.Sprintf("field %s in type %s", e.Field, e.Owner)
+	}
+	first, second := e.Candidates[0], e.Candidates[1]
+	kind := "assignable"
+	if first.Primary && second.Primary {
+		kind = "primary"
+	}
+	return fmt.Sprintf(
+		"found two %s values for %s. one type %s with value %v and another type %s with value %v",
+		kind,
+		target,
+		first.reflectType,
+		first.Value,
+		second.reflectType,
+		second.Value,
+	)
+}
+
+// InvalidTagError 表示字段上的inject或value标签格式错误，Err通常是ErrInvalidTag。
+type InvalidTagError struct {
+	Owner reflect.Type
+	Field string
+	Tag   string
+	Err   error
+}
+
+func (e *InvalidTagError) Error() string {
+	return fmt.Sprintf("unexpected tag format `%s` for field %s in type %s", e.Tag, e.Field, e.Owner)
+}
+
+func (e *InvalidTagError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidFieldError 表示标签用在了不支持的字段上，例如未导出的字段或非结构体的inline字段。
+type InvalidFieldError struct {
+	Owner  reflect.Type
+	Field  string
+	format string // 以字段名称和类型格式化的错误信息
+}
+
+func (e *InvalidFieldError) Error() string {
+	return fmt.Sprintf(e.format, e.Field, e.Owner)
+}
+
+// DuplicateProvideError 表示同一个名称或类型被提供了两次。
+type DuplicateProvideError struct {
+	Type         reflect.Type   // 重复的类型，按名称重复时为后提供的对象类型
+	Name         string         // 重复的名称
+	Existing     *Object        // 已经提供的对象，与构造函数冲突时可能为nil
+	Constructors []*Constructor // 冲突涉及的构造函数
+}
+
+func (e *DuplicateProvideError) Error() string {
+	switch {
+	case e.Name != "":
+		return fmt.Sprintf("provided two instances named %s", e.Name)
+	case len(e.Constructors) > 1:
+		return fmt.Sprintf("provided two constructors of type %s", e.Type)
+	case len(e.Constructors) == 1:
+		return fmt.Sprintf("provided an unnamed instance and a constructor of type %s", e.Type)
+	}
+	return fmt.Sprintf("provided two unnamed instances of type *%s.%s", e.Type.Elem().PkgPath(), e.Type.Elem().Name())
+}
@@ -0,0 +1,232 @@
+package inject_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+func TestMissingDependencyError(t *testing.T) {
+	var v TypeInjectInterfaceMissing
+	err := inject.Populate(&v)
+
+	var missing *inject.MissingDependencyError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected a missing dependency error but got %v", err)
+	}
+	if missing.Owner != reflect.TypeOf(&v) || missing.Field != "Answerable" {
+		t.Fatalf("unexpected owner %s and field %s", missing.Owner, missing.Field)
+	}
+	if missing.Type != reflect.TypeOf((*Answerable)(nil)).Elem() {
+		t.Fatalf("unexpected type %s", missing.Type)
+	}
+}
+
+type TypeWithMissingNamedDependency struct {
+	A *TypeAnswerStruct `inject:"foo"`
+}
+
+func TestMissingNamedDependencyError(t *testing.T) {
+	var v TypeWithMissingNamedDependency
+	err := inject.Populate(&v)
+
+	var missing *inject.MissingDependencyError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected a missing dependency error but got %v", err)
+	}
+	if missing.Name != "foo" || missing.Field != "A" {
+		t.Fatalf("unexpected name %s and field %s", missing.Name, missing.Field)
+	}
+
+	const msg = "did not find object named foo required by field A in type *inject_test.TypeWithMissingNamedDependency"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+func TestMissingPropertyError(t *testing.T) {
+	var v TypeWithMissingProperty
+	err := inject.Populate(&v)
+
+	var missing *inject.MissingDependencyError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected a missing dependency error but got %v", err)
+	}
+	if missing.Property != "server.port" || missing.Type != reflect.TypeOf(0) {
+		t.Fatalf("unexpected property %s of type %s", missing.Property, missing.Type)
+	}
+}
+
+func TestAmbiguousDependencyError(t *testing.T) {
+	var v TypeInjectInterface
+	a := &TypeAnswerStruct{}
+	n := &TypeNestedStruct{}
+	err := inject.Populate(&v, a, n)
+
+	var ambiguous *inject.AmbiguousDependencyError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("expected an ambiguous dependency error but got %v", err)
+	}
+	if ambiguous.Field != "Answerable" || len(ambiguous.Candidates) != 2 {
+		t.Fatalf("unexpected field %s with candidates %v", ambiguous.Field, ambiguous.Candidates)
+	}
+	if ambiguous.Candidates[0].Value != a || ambiguous.Candidates[1].Value != n {
+		t.Fatalf("unexpected candidates %v", ambiguous.Candidates)
+	}
+}
+
+func TestAmbiguousConstructorError(t *testing.T) {
+	c := inject.NewContainer()
+	if err := c.ProvideConstructor(func() *TypeAnswerStruct { return &TypeAnswerStruct{} }); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideConstructor(func() *TypeNestedStruct { return &TypeNestedStruct{} }); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := inject.Resolve[Answerable](c)
+	var ambiguous *inject.AmbiguousDependencyError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("expected an ambiguous dependency error but got %v", err)
+	}
+	if len(ambiguous.Constructors) != 2 {
+		t.Fatalf("unexpected constructors %v", ambiguous.Constructors)
+	}
+}
+
+type TypeWithInvalidTagOption struct {
+	A *TypeAnswerStruct `inject:",unknown"`
+}
+
+func TestInvalidTagError(t *testing.T) {
+	var v TypeWithInvalidTagOption
+	err := inject.Populate(&v)
+
+	var invalid *inject.InvalidTagError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected an invalid tag error but got %v", err)
+	}
+	if invalid.Field != "A" || invalid.Tag != `inject:",unknown"` {
+		t.Fatalf("unexpected field %s with tag %s", invalid.Field, invalid.Tag)
+	}
+	if !errors.Is(err, inject.ErrInvalidTag) {
+		t.Fatalf("expected %v to wrap ErrInvalidTag", err)
+	}
+}
+
+func TestInvalidFieldError(t *testing.T) {
+	var v TypeWithValueOnUnexportedField
+	err := inject.Populate(&v)
+
+	var invalid *inject.InvalidFieldError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected an invalid field error but got %v", err)
+	}
+	if invalid.Owner != reflect.TypeOf(&v) || invalid.Field != "port" {
+		t.Fatalf("unexpected owner %s and field %s", invalid.Owner, invalid.Field)
+	}
+}
+
+func TestDuplicateProvideError(t *testing.T) {
+	var g inject.Graph
+	first := &inject.Object{Value: &TypeAnswerStruct{}, Name: "foo"}
+	err := g.Provide(first, &inject.Object{Value: &TypeNestedStruct{}, Name: "foo"})
+
+	var duplicate *inject.DuplicateProvideError
+	if !errors.As(err, &duplicate) {
+		t.Fatalf("expected a duplicate provide error but got %v", err)
+	}
+	if duplicate.Name != "foo" || duplicate.Existing != first {
+		t.Fatalf("unexpected name %s and existing %v", duplicate.Name, duplicate.Existing)
+	}
+}
+
+func TestDuplicateConstructorError(t *testing.T) {
+	c := inject.NewContainer()
+	if err := c.Provides(&TypeAnswerStruct{}); err != nil {
+		t.Fatal(err)
+	}
+	err := c.ProvideConstructor(func() *TypeAnswerStruct { return &TypeAnswerStruct{} })
+
+	var duplicate *inject.DuplicateProvideError
+	if !errors.As(err, &duplicate) {
+		t.Fatalf("expected a duplicate provide error but got %v", err)
+	}
+	if duplicate.Existing == nil || len(duplicate.Constructors) != 1 {
+		t.Fatalf("unexpected existing %v and constructors %v", duplicate.Existing, duplicate.Constructors)
+	}
+}
+
+type TypeWithManyErrors struct {
+	Missing     Answerable        `inject:""`
+	Named       *TypeAnswerStruct `inject:"foo"`
+	Unsupported int               `inject:""`
+}
+
+type TypeWithMoreErrors struct {
+	Tag    *TypeAnswerStruct   `inject:",bogus"`
+	Nested *TypeWithManyErrors `inject:"private"`
+}
+
+func TestCollectAllErrors(t *testing.T) {
+	g := inject.Graph{CollectAllErrors: true}
+	var v TypeWithMoreErrors
+	if err := g.Provide(&inject.Object{Value: &v}); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Populate()
+
+	var errs inject.PopulateErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected populate errors but got %v", err)
+	}
+	const msg = "found no assignable value for field Missing in type *inject_test.TypeWithManyErrors\n" +
+		"did not find object named foo required by field Named in type *inject_test.TypeWithManyErrors\n" +
+		"found inject tag on unsupported field Unsupported in type *inject_test.TypeWithManyErrors\n" +
+		"unexpected tag format `inject:\",bogus\"` for field Tag in type *inject_test.TypeWithMoreErrors"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+
+	var missing *inject.MissingDependencyError
+	if !errors.As(err, &missing) || missing.Field != "Missing" {
+		t.Fatalf("expected to find the missing dependency in %v", err)
+	}
+	if !errors.Is(err, inject.ErrInvalidTag) {
+		t.Fatalf("expected %v to wrap ErrInvalidTag", err)
+	}
+	if v.Nested == nil {
+		t.Fatal("expected the valid fields to be populated")
+	}
+}
+
+func TestCollectAllErrorsNamedInterface(t *testing.T) {
+	g := inject.Graph{CollectAllErrors: true}
+	var v struct {
+		Store   Answerable `inject:"missing"`
+		Missing Answerable `inject:""`
+	}
+	if err := g.Provide(&inject.Object{Value: &v}); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Populate()
+
+	var errs inject.PopulateErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("expected two populate errors but got %v", err)
+	}
+	var missing *inject.MissingDependencyError
+	if !errors.As(errs[1], &missing) || missing.Name != "missing" {
+		t.Fatalf("expected the missing named dependency to be collected but got %v", errs[1])
+	}
+}
+
+func TestCollectAllErrorsDisabled(t *testing.T) {
+	var v TypeWithMoreErrors
+	err := inject.Populate(&v)
+	if _, ok := err.(inject.PopulateErrors); ok {
+		t.Fatalf("expected only the first error but got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package inject
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// exportNode 导出的依赖图节点
+type exportNode struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name,omitempty"`
+	Private  bool   `json:"private"`
+	Created  bool   `json:"created"`
+	Embedded bool   `json:"embedded"`
+}
+
+// exportEdge 导出的依赖图的边，从依赖方指向被注入的对象
+type exportEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Field string `json:"field"`
+}
+
+type exportGraph struct {
+	Nodes []exportNode `json:"nodes"`
+	Edges []exportEdge `json:"edges"`
+}
+
+// export 以提供顺序返回所有节点，以及按字段名称排序的边。指向父级中对象的边被忽略，
+// 指向装饰器或代理包装后的值的边指向被包装的对象。
+func (g *Graph) export() exportGraph {
+	objects := make([]*Object, 0, len(g.unnamed)+len(g.named))
+	objects = append(objects, g.unnamed...)
+	for _, o := range g.named {
+		objects = append(objects, o)
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].seq < objects[j].seq
+	})
+
+	result := exportGraph{
+		Nodes: make([]exportNode, 0, len(objects)),
+		Edges: []exportEdge{},
+	}
+	for _, o := range objects {
+		result.Nodes = append(result.Nodes, exportNode{
+			ID:       nodeID(o),
+			Type:     fmt.Sprint(o.reflectType),
+			Name:     o.Name,
+			Private:  o.private,
+			Created:  o.created,
+			Embedded: o.embedded,
+		})
+
+		fields := make([]string, 0, len(o.Fields))
+		for field := range o.Fields {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			// 包装后的值不在依赖图中，边指向被包装的原始对象。
+			dep := unwrap(o.Fields[field])
+			if !g.owns(dep) {
+				continue
+			}
+			result.Edges = append(result.Edges, exportEdge{
+				From:  nodeID(o),
+				To:    nodeID(dep),
+				Field: field,
+			})
+		}
+	}
+	return result
+}
+
+func nodeID(o *Object) string {
+	return fmt.Sprintf("n%d", o.seq)
+}
+
+// MarshalJSON 将依赖图编码为包含nodes和edges的JSON
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.export())
+}
+
+// WriteDOT 以Graphviz DOT格式输出依赖图。
+// 私有对象使用虚线，由我们创建的对象使用圆角框，嵌入结构体使用点线。
+func (g *Graph) WriteDOT(w io.Writer) error {
+	exported := g.export()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph inject {")
+	fmt.Fprintln(bw, "\tnode [shape=box];")
+	for _, n := range exported.Nodes {
+		lines := nodeLabel(n)
+		for i, line := range lines {
+			lines[i] = dotEscaper.Replace(line)
+		}
+		attrs := []string{fmt.Sprintf(`label="%s"`, strings.Join(lines, `\n`))}
+		var styles []string
+		if n.Private {
+			styles = append(styles, "dashed")
+		}
+		if n.Created {
+			styles = append(styles, "rounded")
+		}
+		if n.Embedded {
+			styles = append(styles, "dotted")
+		}
+		if len(styles) > 0 {
+			attrs = append(attrs, fmt.Sprintf("style=%s", dotQuote(strings.Join(styles, ","))))
+		}
+		fmt.Fprintf(bw, "\t%s [%s];\n", n.ID, strings.Join(attrs, ", "))
+	}
+	for _, e := range exported.Edges {
+		fmt.Fprintf(bw, "\t%s -> %s [label=%s];\n", e.From, e.To, dotQuote(e.Field))
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// WriteMermaid 以Mermaid流程图格式输出依赖图。
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	exported := g.export()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "graph TD")
+	for _, n := range exported.Nodes {
+		fmt.Fprintf(bw, "\t%s[%s]\n", n.ID, mermaidQuote(strings.Join(nodeLabel(n), "<br/>")))
+	}
+	for _, e := range exported.Edges {
+		fmt.Fprintf(bw, "\t%s -->|%s| %s\n", e.From, mermaidQuote(e.Field), e.To)
+	}
+	return bw.Flush()
+}
+
+// nodeLabel 返回节点标签的各行：类型、名称以及标记。
+func nodeLabel(n exportNode) []string {
+	lines := []string{n.Type}
+	if n.Name != "" {
+		lines = append(lines, "named "+n.Name)
+	}
+	var flags []string
+	if n.Private {
+		flags = append(flags, "private")
+	}
+	if n.Created {
+		flags = append(flags, "created")
+	}
+	if n.Embedded {
+		flags = append(flags, "embedded")
+	}
+	if len(flags) > 0 {
+		lines = append(lines, "("+strings.Join(flags, ", ")+")")
+	}
+	return lines
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,125 @@
+package inject_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeForExport struct {
+	A *TypeAnswerStruct `inject:"foo"`
+	B *TypeNestedStruct `inject:""`
+	C *TypeAnswerStruct `inject:"private"`
+}
+
+func newExportGraph(t *testing.T) *inject.Graph {
+	var g inject.Graph
+	err := g.Provide(
+		&inject.Object{Value: &TypeAnswerStruct{}, Name: "foo"},
+		&inject.Object{Value: &TypeForExport{}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	return &g
+}
+
+func TestWriteDOT(t *testing.T) {
+	g := newExportGraph(t)
+	var buf bytes.Buffer
+	if err := g.WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	const expected = `digraph inject {
+	node [shape=box];
+	n1 [label="*inject_test.TypeAnswerStruct\nnamed foo"];
+	n2 [label="*inject_test.TypeForExport"];
+	n3 [label="*inject_test.TypeNestedStruct\n(created)", style="rounded"];
+	n4 [label="*inject_test.TypeAnswerStruct\n(created)", style="rounded"];
+	n5 [label="*inject_test.TypeAnswerStruct\n(private, created)", style="dashed,rounded"];
+	n2 -> n1 [label="A"];
+	n2 -> n3 [label="B"];
+	n2 -> n5 [label="C"];
+	n3 -> n4 [label="A"];
+}
+`
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\nactual:\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	g := newExportGraph(t)
+	var buf bytes.Buffer
+	if err := g.WriteMermaid(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	const expected = `graph TD
+	n1["*inject_test.TypeAnswerStruct<br/>named foo"]
+	n2["*inject_test.TypeForExport"]
+	n3["*inject_test.TypeNestedStruct<br/>(created)"]
+	n4["*inject_test.TypeAnswerStruct<br/>(created)"]
+	n5["*inject_test.TypeAnswerStruct<br/>(private, created)"]
+	n2 -->|"A"| n1
+	n2 -->|"B"| n3
+	n2 -->|"C"| n5
+	n3 -->|"A"| n4
+`
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\nactual:\n%s", expected, buf.String())
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	g := newExportGraph(t)
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const expected = `{"nodes":[` +
+		`{"id":"n1","type":"*inject_test.TypeAnswerStruct","name":"foo","private":false,"created":false,"embedded":false},` +
+		`{"id":"n2","type":"*inject_test.TypeForExport","private":false,"created":false,"embedded":false},` +
+		`{"id":"n3","type":"*inject_test.TypeNestedStruct","private":false,"created":true,"embedded":false},` +
+		`{"id":"n4","type":"*inject_test.TypeAnswerStruct","private":false,"created":true,"embedded":false},` +
+		`{"id":"n5","type":"*inject_test.TypeAnswerStruct","private":true,"created":true,"embedded":false}],` +
+		`"edges":[` +
+		`{"from":"n2","to":"n1","field":"A"},` +
+		`{"from":"n2","to":"n3","field":"B"},` +
+		`{"from":"n2","to":"n5","field":"C"},` +
+		`{"from":"n3","to":"n4","field":"A"}]}`
+	if string(data) != expected {
+		t.Fatalf("expected:\n%s\nactual:\n%s", expected, string(data))
+	}
+}
+
+func TestWriteDOTEscapesLabels(t *testing.T) {
+	var g inject.Graph
+	var v struct {
+		Inline struct {
+			A *TypeAnswerStruct `inject:""`
+		} `inject:"inline"`
+	}
+	if err := g.Provide(&inject.Object{Value: &v}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := g.WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	const label = `n2 [label="*struct { A *inject_test.TypeAnswerStruct \"inject:\\\"\\\"\" }\n(private)", style="dashed"];`
+	if !bytes.Contains(buf.Bytes(), []byte(label)) {
+		t.Fatalf("expected label:\n%s\nactual:\n%s", label, buf.String())
+	}
+}
@@ -0,0 +1,122 @@
+package inject
+
+import "reflect"
+
+// typeIndex 未命名对象的索引，使按类型查找和深度注入的去重不必遍历所有对象。
+// 所有列表都保持提供顺序。
+type typeIndex struct {
+	byType     map[reflect.Type][]*Object        // 非私有对象按具体类型分组
+	byIface    map[reflect.Type]*ifaceCandidates // 按接口类型缓存的可分配对象
+	implements map[[2]reflect.Type]bool          // 缓存具体类型是否实现了接口
+	values     map[interface{}]bool              // 所有未命名对象（包括私有的）的值
+}
+
+// ifaceCandidates 可分配给某个接口的非私有对象，只包含g.unnamed中前scanned个对象的结果。
+type ifaceCandidates struct {
+	objects []*Object
+	scanned int
+}
+
+// indexObject 将刚刚加入g.unnamed的o加入索引。
+func (g *Graph) indexObject(o *Object) {
+	if g.index.values == nil {
+		g.index.values = make(map[interface{}]bool)
+		g.index.byType = make(map[reflect.Type][]*Object)
+	}
+	// 构造函数创建的函数等实现不能作为map的键，它们也不会通过深度注入重复提供。
+	if o.reflectType.Comparable() {
+		g.index.values[o.Value] = true
+	}
+	if !o.private {
+		g.index.byType[o.reflectType] = append(g.index.byType[o.reflectType], o)
+	}
+}
+
+// reindex 在g.unnamed被整体替换后重建索引。
+func (g *Graph) reindex() {
+	g.index = typeIndex{}
+	for _, o := range g.unnamed {
+		g.indexObject(o)
+	}
+}
+
+// provided 返回值为v的对象是否已经作为未命名对象加入了依赖图。
+func (g *Graph) provided(v interface{}) bool {
+	return g.index.values[v]
+}
+
+// candidates 以提供顺序返回所有可分配给t的非私有未命名对象，调用方不能修改返回的切片。
+func (g *Graph) candidates(t reflect.Type) []*Object {
+	switch t.Kind() {
+	case reflect.Ptr:
+		// 未命名对象都是结构体指针，只能分配给相同的指针类型或以其为底层类型的命名类型。
+		return g.index.byType[reflect.PointerTo(t.Elem())]
+	case reflect.Interface:
+	default:
+		return nil
+	}
+
+	if g.index.byIface == nil {
+		g.index.byIface = make(map[reflect.Type]*ifaceCandidates)
+		g.index.implements = make(map[[2]reflect.Type]bool)
+	}
+	entry := g.index.byIface[t]
+	if entry == nil {
+		entry = &ifaceCandidates{}
+		g.index.byIface[t] = entry
+	}
+	for _, o := range g.unnamed[entry.scanned:] {
+		if o.private {
+			continue
+		}
+		key := [2]reflect.Type{o.reflectType, t}
+		ok, cached := g.index.implements[key]
+		if !cached {
+			ok = o.reflectType.Implements(t)
+			g.index.implements[key] = ok
+		}
+		if ok {
+			entry.objects = append(entry.objects, o)
+		}
+	}
+	entry.scanned = len(g.unnamed)
+	return entry.objects
+}
+
+// firstAssignable 返回第一个可分配给t的非私有未命名对象，主要对象优先，g自己无法提供t时在父级中查找。
+func (g *Graph) firstAssignable(t reflect.Type) *Object {
+	if found := g.visible(g.candidates(t)); len(found) > 0 {
+		return preferPrimary(found)[0]
+	}
+	if g.inherits(t) {
+		return g.parent.firstAssignable(t)
+	}
+	return nil
+}
+
+// assignable 以提供顺序返回所有可分配给t的非私有未命名对象。
+// 如果g自己无法提供t，则返回父级中的对象。
+func (g *Graph) assignable(t reflect.Type) []*Object {
+	if g.inherits(t) {
+		return g.parent.assignable(t)
+	}
+	return append([]*Object(nil), g.visible(g.candidates(t))...)
+}
+
+// preferPrimary 在有多个候选对象时只保留主要对象。没有主要对象时原样返回，
+// 有多个主要对象时返回所有主要对象，由调用方报告歧义。
+func preferPrimary(found []*Object) []*Object {
+	if len(found) < 2 {
+		return found
+	}
+	var primary []*Object
+	for _, o := range found {
+		if o.Primary {
+			primary = append(primary, o)
+		}
+	}
+	if len(primary) == 0 {
+		return found
+	}
+	return primary
+}
@@ -0,0 +1,203 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// linearAssignable 是建立索引之前遍历所有对象的查找方式，用于对比。
+func linearAssignable(g *Graph, t reflect.Type) []*Object {
+	var found []*Object
+	for _, existing := range g.unnamed {
+		if existing.private {
+			continue
+		}
+		if existing.reflectType.AssignableTo(t) {
+			found = append(found, existing)
+		}
+	}
+	return found
+}
+
+// linearProvided 是建立索引之前深度注入检查对象是否已经存在的方式，用于对比。
+func linearProvided(g *Graph, v interface{}) bool {
+	for _, existing := range g.unnamed {
+		if existing.Value == v {
+			return true
+		}
+	}
+	return false
+}
+
+type benchmarkAnswer struct{}
+
+func (*benchmarkAnswer) Answer() int { return 42 }
+
+type benchmarkAnswerable interface {
+	Answer() int
+}
+
+// benchmarkTypes 创建n个不同的结构体类型。如果deps大于0，后一半类型注入前一半中deps个类型的指针
+// 以及一个接口，而不是各自独立。
+func benchmarkTypes(n, deps int) []reflect.Type {
+	types := make([]reflect.Type, n)
+	leaves := n
+	if deps > 0 {
+		leaves = n / 2
+	}
+	for i := range types {
+		fields := []reflect.StructField{{
+			Name: "ID",
+			Type: reflect.TypeOf(0),
+			Tag:  reflect.StructTag(fmt.Sprintf(`id:"%d"`, i)),
+		}}
+		if i >= leaves {
+			for j := 0; j < deps; j++ {
+				fields = append(fields, reflect.StructField{
+					Name: fmt.Sprintf("Dep%d", j),
+					Type: reflect.PointerTo(types[(i+j)%leaves]),
+					Tag:  `inject:""`,
+				})
+			}
+			fields = append(fields, reflect.StructField{
+				Name: "Answer",
+				Type: reflect.TypeOf((*benchmarkAnswerable)(nil)).Elem(),
+				Tag:  `inject:""`,
+			})
+		}
+		types[i] = reflect.StructOf(fields)
+	}
+	return types
+}
+
+func benchmarkGraph(b *testing.B, types []reflect.Type) *Graph {
+	var g Graph
+	for _, t := range types {
+		if err := g.Provide(&Object{Value: reflect.New(t).Interface()}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := g.Provide(&Object{Value: &benchmarkAnswer{}}); err != nil {
+		b.Fatal(err)
+	}
+	return &g
+}
+
+func TestIndexMatchesLinearLookup(t *testing.T) {
+	var g Graph
+	a := &benchmarkAnswer{}
+	objects := []*Object{
+		{Value: &struct{ A int }{}},
+		{Value: a},
+		{Value: &struct{ B int }{}, private: true},
+		{Value: &benchmarkAnswer{}, private: true},
+	}
+	if err := g.Provide(objects...); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, typ := range []reflect.Type{
+		reflect.TypeOf(a),
+		reflect.TypeOf(&struct{ A int }{}),
+		reflect.TypeOf(&struct{ B int }{}),
+		reflect.TypeOf((*benchmarkAnswerable)(nil)).Elem(),
+		reflect.TypeOf((*fmt.Stringer)(nil)).Elem(),
+		reflect.TypeOf(0),
+	} {
+		indexed := g.assignable(typ)
+		linear := linearAssignable(&g, typ)
+		if !reflect.DeepEqual(indexed, linear) {
+			t.Fatalf("lookup of %s: expected %v but got %v", typ, linear, indexed)
+		}
+	}
+
+	// 缓存的接口查找结果应该包含之后提供的对象。
+	later := &Object{Value: &struct {
+		benchmarkAnswer
+	}{}}
+	if err := g.Provide(later); err != nil {
+		t.Fatal(err)
+	}
+	iface := reflect.TypeOf((*benchmarkAnswerable)(nil)).Elem()
+	if found := g.assignable(iface); len(found) != 2 || found[1] != later {
+		t.Fatalf("expected cached lookup to include later objects but got %v", found)
+	}
+
+	for _, o := range objects {
+		if !g.provided(o.Value) || !linearProvided(&g, o.Value) {
+			t.Fatalf("expected %v to be provided", o)
+		}
+	}
+	if g.provided(&benchmarkAnswer{}) {
+		t.Fatal("unexpected provided value")
+	}
+}
+
+func BenchmarkAssignablePointer(b *testing.B) {
+	for _, n := range []int{100, 2000} {
+		types := benchmarkTypes(n, 0)
+		g := benchmarkGraph(b, types)
+		target := reflect.PointerTo(types[n-1])
+		b.Run(fmt.Sprintf("linear/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				linearAssignable(g, target)
+			}
+		})
+		b.Run(fmt.Sprintf("indexed/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.firstAssignable(target)
+			}
+		})
+	}
+}
+
+func BenchmarkAssignableInterface(b *testing.B) {
+	iface := reflect.TypeOf((*benchmarkAnswerable)(nil)).Elem()
+	for _, n := range []int{100, 2000} {
+		g := benchmarkGraph(b, benchmarkTypes(n, 0))
+		b.Run(fmt.Sprintf("linear/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				linearAssignable(g, iface)
+			}
+		})
+		b.Run(fmt.Sprintf("indexed/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.candidates(iface)
+			}
+		})
+	}
+}
+
+func BenchmarkProvided(b *testing.B) {
+	for _, n := range []int{100, 2000} {
+		g := benchmarkGraph(b, benchmarkTypes(n, 0))
+		missing := &benchmarkAnswer{}
+		b.Run(fmt.Sprintf("linear/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				linearProvided(g, missing)
+			}
+		})
+		b.Run(fmt.Sprintf("indexed/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.provided(missing)
+			}
+		})
+	}
+}
+
+func BenchmarkPopulate(b *testing.B) {
+	for _, n := range []int{100, 2000} {
+		types := benchmarkTypes(n, 3)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				g := benchmarkGraph(b, types)
+				b.StartTimer()
+				if err := g.Populate(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"math/rand"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
 )
 
 type Logger interface {
@@ -27,12 +30,19 @@ type Object struct {
 	Value        interface{}
 	Name         string             // 可选的名称
 	Complete     bool               // 如果为true，该Value将被视为完整的
+	Scope        Scope              // 可选的，默认为Singleton
+	Qualifiers   map[string]string  // 可选的，用于通过qualifier选项选择对象，带有限定符的同类型对象可以共存
+	Primary      bool               // 如果为true，有多个可分配的对象时优先选择该Value
 	Fields       map[string]*Object // 填充已注入的字段名称及其对应的*Object
 	reflectType  reflect.Type
 	reflectValue reflect.Value
-	private      bool // 如果为true，该Value将不会被使用，只会被填充
-	created      bool // 如果为true，该Object是由我们创建的
-	embedded     bool // 如果为true，该Object是内部提供的嵌入结构体
+	private      bool    // 如果为true，该Value将不会被使用，只会被填充
+	created      bool    // 如果为true，该Object是由我们创建的
+	embedded     bool    // 如果为true，该Object是内部提供的嵌入结构体
+	seq          int     // 提供的顺序
+	module       *Module // 提供该对象的模块
+	hidden       bool    // 如果为true，该Value只能注入到module中的对象
+	decorated    bool    // 如果为true，该Value是装饰器包装后的值，不在依赖图中
 }
 
 func (o *Object) String() string {
@@ -52,10 +62,31 @@ func (o *Object) addDep(field string, dep *Object) {
 }
 
 type Graph struct {
-	Logger      Logger // 可选的，将触发信息日志
-	unnamed     []*Object
-	unnamedType map[reflect.Type]bool
-	named       map[string]*Object
+	Logger     Logger           // 可选的，将触发信息日志
+	Properties []PropertySource // 可选的，value标签使用的配置来源，先提供的优先
+	// 可选的，如果为true，Populate会检查所有对象的所有字段，并以PopulateErrors一次性返回所有错误
+	CollectAllErrors bool
+	unnamed          []*Object
+	unnamedType      map[reflect.Type]bool
+	named            map[string]*Object
+	constructors     []*Constructor
+	path             []pathEntry  // 正在解析的依赖路径
+	seq              int          // 已提供的对象数量，用于记录提供顺序
+	errs             []fieldError // CollectAllErrors模式下收集的错误
+	plan             *Plan        // 正在计算的注入计划
+	index            typeIndex    // 未命名对象的索引
+	parent           *Graph       // 找不到的类型和名称在父级中查找
+	scoped           bool         // 是否是请求作用域的依赖图
+	locker           sync.Locker  // Lazy和Provider在Get时查找依赖使用的锁
+	modules          map[*Module]bool
+	viewer           *Module // 正在填充的对象所属的模块
+	hidden           int     // 模块私有对象的数量
+	decorators       map[reflect.Type][]*Decorator
+	decorated        map[decoration]*Object // 已经包装过的对象
+	interceptions    []*Interception
+	deferInits       bool              // 如果为true，原型和请求作用域实例的Init推迟到释放locker之后调用
+	inits            []*Object         // 等待调用Init的实例，只记录在最上层的依赖图中
+	tracker          *lifecycleTracker // 由容器设置，跟踪容器启动后新创建的单例
 }
 
 func (g *Graph) Provide(objects ...*Object) error {
@@ -66,9 +97,18 @@ func (g *Graph) Provide(objects ...*Object) error {
 		if o.Fields != nil {
 			return fmt.Errorf("fields were specified on object %v when it was provided", o)
 		}
+		if o.Scope != Singleton && !o.created {
+			if err := g.provideScoped(o); err != nil {
+				return err
+			}
+			continue
+		}
+		g.seq++
+		o.seq = g.seq
 
 		if o.Name == "" {
-			if !isStructPtr(o.reflectType) {
+			// 返回接口的构造函数可以创建任何实现，例如函数类型。
+			if !isStructPtr(o.reflectType) && !o.created {
 				return fmt.Errorf(
 					"expected unnamed object value to be a pointer to a struct but got type %s with value %v",
 					o.reflectType,
@@ -80,23 +120,38 @@ func (g *Graph) Provide(objects ...*Object) error {
 					g.unnamedType = make(map[reflect.Type]bool)
 				}
 
-				if g.unnamedType[o.reflectType] {
-					return fmt.Errorf(
-						"provided two unnamed instances of type *%s.%s",
-						o.reflectType.Elem().PkgPath(),
-						o.reflectType.Elem().Name(),
-					)
+				if len(o.Qualifiers) == 0 && !o.hidden && g.unnamedType[o.reflectType] {
+					return &DuplicateProvideError{
+						Type:     o.reflectType,
+						Existing: g.firstAssignable(o.reflectType),
+					}
+				}
+				if !o.created {
+					for _, c := range g.constructors {
+						if c.out == o.reflectType {
+							return &DuplicateProvideError{
+								Type:         c.out,
+								Constructors: []*Constructor{c},
+							}
+						}
+					}
+				}
+				if len(o.Qualifiers) == 0 && !o.hidden {
+					g.unnamedType[o.reflectType] = true
+				}
+				if o.hidden {
+					g.hidden++
 				}
-				g.unnamedType[o.reflectType] = true
 			}
 			g.unnamed = append(g.unnamed, o)
+			g.indexObject(o)
 		} else {
 			if g.named == nil {
 				g.named = make(map[string]*Object)
 			}
 
-			if g.named[o.Name] != nil {
-				return fmt.Errorf("provided two instances named %s", o.Name)
+			if existing := g.named[o.Name]; existing != nil {
+				return &DuplicateProvideError{Type: o.reflectType, Name: o.Name, Existing: existing}
 			}
 			g.named[o.Name] = o
 		}
@@ -122,6 +177,8 @@ func (g *Graph) provideForDeepInject(o *Object) error {
 	if o.Fields != nil {
 		return fmt.Errorf("fields were specified on object %v when it was provided", o)
 	}
+	g.seq++
+	o.seq = g.seq
 
 	if o.Name == "" {
 		if !isStructPtr(o.reflectType) {
@@ -133,21 +190,20 @@ func (g *Graph) provideForDeepInject(o *Object) error {
 
 		// 对于深度注入，我们不检查类型重复，直接添加到unnamed列表
 		// 但我们需要检查是否已经存在相同的实例（相同的指针）
-		for _, existing := range g.unnamed {
-			if existing.Value == o.Value {
-				// 相同的实例已存在，不需要重复添加
-				return nil
-			}
+		if g.provided(o.Value) {
+			// 相同的实例已存在，不需要重复添加
+			return nil
 		}
 
 		g.unnamed = append(g.unnamed, o)
+		g.indexObject(o)
 	} else {
 		if g.named == nil {
 			g.named = make(map[string]*Object)
 		}
 
-		if g.named[o.Name] != nil {
-			return fmt.Errorf("provided two instances named %s", o.Name)
+		if existing := g.named[o.Name]; existing != nil {
+			return &DuplicateProvideError{Type: o.reflectType, Name: o.Name, Existing: existing}
 		}
 		g.named[o.Name] = o
 	}
@@ -197,7 +253,11 @@ func (g *Graph) Populate() error {
 	}
 
 	// 第二遍处理接口值的注入，以确保我们首先创建了所有具体类型。
-	for _, o := range g.unnamed {
+	if err := g.populateInterfaces(0); err != nil {
+		return err
+	}
+
+	for _, o := range g.named {
 		if o.Complete {
 			continue
 		}
@@ -207,7 +267,14 @@ func (g *Graph) Populate() error {
 		}
 	}
 
-	for _, o := range g.named {
+	return g.collectedErrors()
+}
+
+// populateInterfaces 对从第from个开始的未命名对象进行接口注入。
+// 构造函数可能在这一过程中追加新的对象，它们同样会被处理。
+func (g *Graph) populateInterfaces(from int) error {
+	for i := from; i < len(g.unnamed); i++ {
+		o := g.unnamed[i]
 		if o.Complete {
 			continue
 		}
@@ -215,313 +282,526 @@ func (g *Graph) Populate() error {
 		if err := g.populateUnnamedInterface(o); err != nil {
 			return err
 		}
+		if o.Scope != Singleton {
+			if err := g.initScoped(o); err != nil {
+				return err
+			}
+		}
 	}
+	return nil
+}
 
+// fail 处理o的第i个字段上的错误。在CollectAllErrors模式下错误被记录下来并返回nil，
+// 以便继续处理其余的字段；否则原样返回错误。
+func (g *Graph) fail(o *Object, i int, err error) error {
+	if !g.CollectAllErrors {
+		return err
+	}
+	g.errs = append(g.errs, fieldError{
+		owner: o.reflectType,
+		field: o.structInfo().fields[i].name,
+		err:   err,
+	})
 	return nil
 }
 
+// collectedErrors 返回并清空已收集的错误，按类型和字段排序，相同的错误只保留一个。
+func (g *Graph) collectedErrors() error {
+	if len(g.errs) == 0 {
+		return nil
+	}
+	errs := g.errs
+	g.errs = nil
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].owner != errs[j].owner {
+			return errs[i].owner.String() < errs[j].owner.String()
+		}
+		return errs[i].field < errs[j].field
+	})
+
+	result := make(PopulateErrors, 0, len(errs))
+	seen := make(map[string]bool)
+	for _, e := range errs {
+		key := fmt.Sprintf("%s.%s: %v", e.owner, e.field, e.err)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, e.err)
+	}
+	return result
+}
+
 func (g *Graph) populateExplicit(o *Object) error {
-	// 忽略命名的值类型
-	if o.Name != "" && !isStructPtr(o.reflectType) {
+	if g.hidden > 0 {
+		defer g.view(o.module)()
+	}
+
+	// 忽略命名的值类型以及构造函数创建的非结构体实现
+	if !isStructPtr(o.reflectType) {
 		return nil
 	}
 
-StructLoop:
-	for i := 0; i < o.reflectValue.Elem().NumField(); i++ {
-		field := o.reflectValue.Elem().Field(i)
-		fieldType := field.Type()
-		fieldTag := o.reflectType.Elem().Field(i).Tag
-		fieldName := o.reflectType.Elem().Field(i).Name
-		tag, err := parseTag(string(fieldTag))
-		if err != nil {
-			return fmt.Errorf(
-				"unexpected tag format `%s` for field %s in type %s",
-				string(fieldTag),
-				o.reflectType.Elem().Field(i).Name,
-				o.reflectType,
-			)
+	for i := range o.structInfo().fields {
+		if err := g.populateExplicitField(o, i); err != nil {
+			if err := g.fail(o, i, err); err != nil {
+				return err
+			}
 		}
+	}
+	return nil
+}
 
-		// 跳过没有标签的字段。
-		if tag == nil {
-			continue
+// populateExplicitField 对o的第i个字段进行第一遍注入。
+func (g *Graph) populateExplicitField(o *Object, i int) error {
+	info := &o.structInfo().fields[i]
+	field := o.reflectValue.Elem().Field(i)
+	fieldType := info.typ
+	fieldName := info.name
+	tag := info.tag
+	if info.tagErr != nil {
+		return &InvalidTagError{Owner: o.reflectType, Field: fieldName, Tag: info.rawTag, Err: info.tagErr}
+	}
+
+	// 带有value标签的字段从配置来源中填充。
+	if info.valueErr != nil {
+		return &InvalidTagError{Owner: o.reflectType, Field: fieldName, Tag: info.rawTag, Err: info.valueErr}
+	}
+	if info.value {
+		if tag != nil {
+			return &InvalidFieldError{
+				Owner:  o.reflectType,
+				Field:  fieldName,
+				format: "found both inject and value tags on field %s in type %s",
+			}
 		}
+		return g.populateValue(o, fieldName, field, info.valueKey, info.valueDef, info.hasDefault)
+	}
 
-		// 不能用于未导出的字段。
-		if !field.CanSet() {
-			return fmt.Errorf(
-				"inject requested on unexported field %s in type %s",
-				o.reflectType.Elem().Field(i).Name,
-				o.reflectType,
-			)
+	// 跳过没有标签的字段。
+	if tag == nil {
+		return nil
+	}
+
+	// 不能用于未导出的字段。
+	if !info.exported {
+		return &InvalidFieldError{
+			Owner:  o.reflectType,
+			Field:  fieldName,
+			format: "inject requested on unexported field %s in type %s",
 		}
+	}
 
-		// 在结构体以外的任何类型上使用inline标签都被认为是无效的。
-		if tag.Inline && fieldType.Kind() != reflect.Struct {
-			return fmt.Errorf(
-				"inline requested on non inlined field %s in type %s",
-				o.reflectType.Elem().Field(i).Name,
-				o.reflectType,
-			)
+	// Lazy和Provider字段只绑定查找函数，依赖在Get时才查找。
+	if info.deferred {
+		return g.populateDeferred(o, i, tag)
+	}
+
+	// 在结构体以外的任何类型上使用inline标签都被认为是无效的。
+	if tag.Inline && info.kind != reflect.Struct {
+		return &InvalidFieldError{
+			Owner:  o.reflectType,
+			Field:  fieldName,
+			format: "inline requested on non inlined field %s in type %s",
 		}
+	}
 
-		// 不要覆盖现有值，但检查现有值是否需要深度注入。
-		if !isNilOrZero(field, fieldType) {
-			// 如果字段已经有值且是结构体指针，检查是否需要深度注入
-			if isStructPtr(fieldType) && !tag.Private {
-				existingValue := field.Interface()
-				// 检查这个对象是否已经在依赖图中
-				var found bool
-				for _, existing := range g.unnamed {
-					if existing.Value == existingValue {
-						found = true
-						break
-					}
+	// 不要覆盖现有值，但检查现有值是否需要深度注入。
+	if !isNilOrZero(field, fieldType) {
+		// 如果字段已经有值且是结构体指针，检查是否需要深度注入
+		if info.structPtr && !tag.Private {
+			existingValue := field.Interface()
+			// 如果不在依赖图中，添加并递归注入
+			if !g.provided(existingValue) {
+				existingObject := &Object{
+					Value:   existingValue,
+					private: false,
+					created: false,
 				}
-				// 如果不在依赖图中，添加并递归注入
-				if !found {
-					existingObject := &Object{
-						Value:   existingValue,
-						private: false,
-						created: false,
-					}
-					// 对于深度注入，我们需要特殊处理类型重复的情况
-					if err := g.provideForDeepInject(existingObject); err != nil {
-						return fmt.Errorf("failed to provide existing object for deep injection: %v", err)
-					}
-					// 递归填充现有对象的依赖（深度注入）
-					if err := g.populateExplicit(existingObject); err != nil {
-						return err
-					}
-					if g.Logger != nil {
-						g.Logger.Info("deep injected existing %v in field %s of %v", existingObject, o.reflectType.Elem().Field(i).Name, o)
-					}
+				// 对于深度注入，我们需要特殊处理类型重复的情况
+				if err := g.provideForDeepInject(existingObject); err != nil {
+					return fmt.Errorf("failed to provide existing object for deep injection: %v", err)
+				}
+				// 递归填充现有对象的依赖（深度注入）
+				g.follow(o.reflectType, o.Name, fieldName)
+				err := g.populateExplicit(existingObject)
+				g.leave()
+				if err != nil {
+					return err
+				}
+				if g.Logger != nil {
+					g.Logger.Info("deep injected existing %v in field %s of %v", existingObject, fieldName, o)
 				}
 			}
-			continue
 		}
+		return nil
+	}
 
-		// 命名注入必须已经明确提供。
-		if tag.Name != "" {
-			existing := g.named[tag.Name]
-			if existing == nil {
-				return fmt.Errorf(
-					"did not find object named %s required by field %s in type %s",
-					tag.Name,
-					o.reflectType.Elem().Field(i).Name,
-					o.reflectType,
-				)
+	// 命名注入必须已经明确提供。
+	if tag.Name != "" {
+		existing := g.lookupNamed(tag.Name)
+		if existing == nil && tag.Optional {
+			if g.Logger != nil {
+				g.Logger.Info("left optional field %s in %v unset: did not find object named %s", fieldName, o, tag.Name)
+			}
+			return nil
+		}
+		if existing == nil {
+			return &MissingDependencyError{
+				Owner: o.reflectType,
+				Field: fieldName,
+				Type:  fieldType,
+				Name:  tag.Name,
 			}
+		}
 
-			if !existing.reflectType.AssignableTo(fieldType) {
-				return fmt.Errorf(
-					"object named %s of type %s is not assignable to field %s (%s) in type %s",
-					tag.Name,
-					fieldType,
-					o.reflectType.Elem().Field(i).Name,
-					existing.reflectType,
-					o.reflectType,
-				)
+		if !existing.reflectType.AssignableTo(fieldType) {
+			return fmt.Errorf(
+				"object named %s of type %s is not assignable to field %s (%s) in type %s",
+				tag.Name,
+				fieldType,
+				fieldName,
+				existing.reflectType,
+				o.reflectType,
+			)
+		}
+		if info.kind == reflect.Interface {
+			var err error
+			if existing, err = g.decorate(fieldType, existing); err != nil {
+				return err
 			}
+		}
 
-			field.Set(reflect.ValueOf(existing.Value))
-			if g.Logger != nil {
-				g.Logger.Info("assigned %v to field %s in %v", existing, o.reflectType.Elem().Field(i).Name, o)
+		g.set(o, fieldName, field, reflect.ValueOf(existing.Value))
+		if g.Logger != nil {
+			g.Logger.Info("assigned %v to field %s in %v", existing, fieldName, o)
+		}
+		g.depend(o, fieldName, existing)
+		return nil
+	}
+
+	// 内联结构体值表示我们想要遍历进入它，但不注入它本身。
+	// 我们需要一个明确的"inline"标签来使其工作
+	if info.kind == reflect.Struct {
+		if tag.Private {
+			return &InvalidFieldError{
+				Owner:  o.reflectType,
+				Field:  fieldName,
+				format: "cannot use private inject on inline struct on field %s in type %s",
 			}
-			o.addDep(fieldName, existing)
-			continue StructLoop
 		}
 
-		// 内联结构体值表示我们想要遍历进入它，但不注入它本身。
-		// 我们需要一个明确的"inline"标签来使其工作
-		if fieldType.Kind() == reflect.Struct {
-			if tag.Private {
-				return fmt.Errorf(
-					"cannot use private inject on inline struct on field %s in type %s",
-					o.reflectType.Elem().Field(i).Name,
-					o.reflectType,
-				)
+		if !tag.Inline {
+			return &InvalidFieldError{
+				Owner:  o.reflectType,
+				Field:  fieldName,
+				format: "inline struct on field %s in type %s required an explicit \"inline\" tag",
 			}
+		}
 
-			if !tag.Inline {
-				return fmt.Errorf(
-					"inline struct on field %s in type %s required an explicit \"inline\" tag",
-					o.reflectType.Elem().Field(i).Name,
-					o.reflectType,
-				)
+		return g.Provide(&Object{
+			Value:    field.Addr().Interface(),
+			private:  true,
+			embedded: info.anonymous,
+		})
+	}
+
+	// 切片只能收集接口或结构体指针类型的元素。
+	if info.kind == reflect.Slice {
+		if elem := fieldType.Elem(); elem.Kind() != reflect.Interface && !isStructPtr(elem) {
+			return &InvalidFieldError{
+				Owner:  o.reflectType,
+				Field:  fieldName,
+				format: "found inject tag on unsupported field %s in type %s",
 			}
+		}
+	}
 
-			if err = g.Provide(&Object{
-				Value:    field.Addr().Interface(),
-				private:  true,
-				embedded: o.reflectType.Elem().Field(i).Anonymous,
-			}); err != nil {
-				return err
+	// 接口和切片注入在第二遍中处理
+	if info.kind == reflect.Interface || info.kind == reflect.Slice {
+		return nil
+	}
+
+	// 私有的Map被直接创建，其他Map在第二遍中按名称收集命名对象
+	if info.kind == reflect.Map {
+		if !tag.Private {
+			if fieldType.Key().Kind() != reflect.String {
+				return &InvalidFieldError{
+					Owner:  o.reflectType,
+					Field:  fieldName,
+					format: "inject on map field %s in type %s must be keyed by string or private",
+				}
 			}
-			continue
+			return nil
 		}
 
-		// 接口注入在第二遍中处理
-		if fieldType.Kind() == reflect.Interface {
-			continue
+		g.set(o, fieldName, field, reflect.MakeMap(fieldType))
+		if g.Logger != nil {
+			g.Logger.Info("made map for field %s in %v", fieldName, o)
 		}
+		return nil
+	}
 
-		// Map被创建并且必须是私有的
-		if fieldType.Kind() == reflect.Map {
-			if !tag.Private {
-				return fmt.Errorf(
-					"inject on map field %s in type %s must be named or private",
-					o.reflectType.Elem().Field(i).Name,
-					o.reflectType,
-				)
-			}
+	// 从这里开始只能注入指针。
+	if !info.structPtr {
+		return &InvalidFieldError{
+			Owner:  o.reflectType,
+			Field:  fieldName,
+			format: "found inject tag on unsupported field %s in type %s",
+		}
+	}
 
-			field.Set(reflect.MakeMap(fieldType))
+	// 除非是私有注入，否则我们将寻找相同类型的现有实例。
+	if !tag.Private {
+		if len(tag.Qualifiers) > 0 {
+			return g.populateQualified(o, i, tag)
+		}
+		existing, ambiguous := pick(fieldType, g.assignable(fieldType))
+		if ambiguous != nil {
+			return &AmbiguousDependencyError{
+				Owner:      o.reflectType,
+				Field:      fieldName,
+				Type:       fieldType,
+				Candidates: ambiguous,
+			}
+		}
+		if existing != nil {
+			g.set(o, fieldName, field, reflect.ValueOf(existing.Value))
 			if g.Logger != nil {
-				g.Logger.Info("made map for field %s in %v", o.reflectType.Elem().Field(i).Name, o)
+				g.Logger.Info("assigned existing %v to field %s in %v", existing, fieldName, o)
 			}
-			continue
+			g.depend(o, fieldName, existing)
+			return nil
 		}
 
-		// 从这里开始只能注入指针。
-		if !isStructPtr(fieldType) {
-			return fmt.Errorf(
-				"found inject tag on unsupported field %s in type %s",
-				o.reflectType.Elem().Field(i).Name,
-				o.reflectType,
-			)
+		// 没有现有实例时，尝试通过构造函数创建。
+		constructor, err := g.findConstructor(fieldType)
+		if err != nil {
+			return err
 		}
-
-		// 除非是私有注入，否则我们将寻找相同类型的现有实例。
-		if !tag.Private {
-			for _, existing := range g.unnamed {
-				if existing.private {
-					continue
-				}
-				if existing.reflectType.AssignableTo(fieldType) {
-					field.Set(reflect.ValueOf(existing.Value))
-					if g.Logger != nil {
-						g.Logger.Info("assigned existing %v to field %s in %v", existing, o.reflectType.Elem().Field(i).Name, o)
-					}
-					o.addDep(fieldName, existing)
-					continue StructLoop
-				}
+		if constructor != nil {
+			if err := g.enter(o.reflectType, o.Name, fieldName); err != nil {
+				return err
+			}
+			constructed, err := g.construct(constructor)
+			g.leave()
+			if err != nil {
+				return err
+			}
+			g.set(o, fieldName, field, reflect.ValueOf(constructed.Value))
+			if g.Logger != nil {
+				g.Logger.Info("assigned constructed %v to field %s in %v", constructed, fieldName, o)
 			}
+			g.depend(o, fieldName, constructed)
+			return nil
 		}
 
-		newValue := reflect.New(fieldType.Elem())
-		newObject := &Object{
-			Value:   newValue.Interface(),
-			private: tag.Private,
-			created: true,
+		// 可选的依赖不会被凭空创建。
+		if tag.Optional {
+			if g.Logger != nil {
+				g.Logger.Info("left optional field %s in %v unset: found no existing value", fieldName, o)
+			}
+			return nil
 		}
 
-		// 将新创建的对象添加到已知对象集合中。
-		if err = g.Provide(newObject); err != nil {
-			return err
+		// 请求作用域中缺少的单例由容器创建和管理。
+		if g.scoped {
+			if err := g.enter(o.reflectType, o.Name, fieldName); err != nil {
+				return err
+			}
+			created, err := g.createInOwner(fieldType)
+			g.leave()
+			if err != nil {
+				return err
+			}
+			g.set(o, fieldName, field, reflect.ValueOf(created.Value))
+			if g.Logger != nil {
+				g.Logger.Info("assigned newly created %v to field %s in %v", created, fieldName, o)
+			}
+			g.depend(o, fieldName, created)
+			return nil
 		}
+	}
+
+	newValue := reflect.New(fieldType.Elem())
+	newObject := &Object{
+		Value:   newValue.Interface(),
+		private: tag.Private,
+		created: true,
+	}
 
+	// 私有注入总是创建新的实例，如果同一个字段已经在创建过程中，
+	// 递归将永远不会终止。
+	if err := g.enter(o.reflectType, o.Name, fieldName); err != nil {
+		return err
+	}
+
+	// 将新创建的对象添加到已知对象集合中。
+	err := g.Provide(newObject)
+	if err == nil {
 		// 递归填充新创建对象的依赖（深度注入）
-		if err = g.populateExplicit(newObject); err != nil {
-			return err
-		}
+		err = g.populateExplicit(newObject)
+	}
+	g.leave()
+	if err != nil {
+		return err
+	}
 
-		// 最后将新创建的对象分配给我们的字段。
-		field.Set(newValue)
-		if g.Logger != nil {
-			g.Logger.Info("assigned newly created %v to field %s in %v", newObject, o.reflectType.Elem().Field(i).Name, o)
-		}
-		o.addDep(fieldName, newObject)
+	// 最后将新创建的对象分配给我们的字段。
+	g.set(o, fieldName, field, newValue)
+	if g.Logger != nil {
+		g.Logger.Info("assigned newly created %v to field %s in %v", newObject, fieldName, o)
 	}
+	g.depend(o, fieldName, newObject)
 	return nil
 }
 
 func (g *Graph) populateUnnamedInterface(o *Object) error {
-	// 忽略命名的值类型.
-	if o.Name != "" && !isStructPtr(o.reflectType) {
+	if g.hidden > 0 {
+		defer g.view(o.module)()
+	}
+
+	// 忽略命名的值类型以及构造函数创建的非结构体实现.
+	if !isStructPtr(o.reflectType) {
 		return nil
 	}
 
-	for i := 0; i < o.reflectValue.Elem().NumField(); i++ {
-		field := o.reflectValue.Elem().Field(i)
-		fieldType := field.Type()
-		fieldTag := o.reflectType.Elem().Field(i).Tag
-		fieldName := o.reflectType.Elem().Field(i).Name
-		tag, err := parseTag(string(fieldTag))
-		if err != nil {
-			return fmt.Errorf(
-				"unexpected tag format `%s` for field %s in type %s",
-				string(fieldTag),
-				o.reflectType.Elem().Field(i).Name,
-				o.reflectType,
-			)
-		}
+	// 计划时对已有对象的赋值被推迟，字段仍然是零值，不能据此跳过已经计划过的字段。
+	if g.plan != nil && !g.plan.visit(o) {
+		return nil
+	}
 
-		// 跳过没有标签的字段。
-		if tag == nil {
-			continue
+	for i := range o.structInfo().fields {
+		if err := g.populateInterfaceField(o, i); err != nil {
+			if err := g.fail(o, i, err); err != nil {
+				return err
+			}
 		}
+	}
+	return nil
+}
 
-		// 我们在这里只处理接口注入。其他情况包括错误
-		// 在第一遍注入指针时处理
-		if fieldType.Kind() != reflect.Interface {
-			continue
+// populateInterfaceField 对o的第i个字段进行第二遍注入，处理接口、切片和Map。
+func (g *Graph) populateInterfaceField(o *Object, i int) error {
+	info := &o.structInfo().fields[i]
+	field := o.reflectValue.Elem().Field(i)
+	fieldType := info.typ
+	fieldName := info.name
+	tag := info.tag
+	if info.tagErr != nil {
+		return &InvalidTagError{Owner: o.reflectType, Field: fieldName, Tag: info.rawTag, Err: info.tagErr}
+	}
+
+	// 跳过没有标签的字段。
+	if tag == nil {
+		return nil
+	}
+
+	// 切片收集所有可分配的对象。
+	if info.kind == reflect.Slice {
+		if err := g.populateSlice(o, i, tag); err != nil {
+			return err
 		}
+		return nil
+	}
 
-		// 接口注入不能是私有的，因为我们无法实例化接口的新实例。
-		if tag.Private {
-			return fmt.Errorf(
-				"found private inject tag on interface field %s in type %s",
-				o.reflectType.Elem().Field(i).Name,
-				o.reflectType,
-			)
+	// 非私有的Map收集所有命名对象。
+	if info.kind == reflect.Map {
+		if !tag.Private {
+			return g.populateMap(o, i)
 		}
+		return nil
+	}
 
-		// 不要覆盖现有值。
-		if !isNilOrZero(field, fieldType) {
-			continue
+	// 我们在这里只处理接口注入。其他情况包括错误
+	// 在第一遍注入指针时处理
+	if info.kind != reflect.Interface {
+		return nil
+	}
+
+	// 接口注入不能是私有的，因为我们无法实例化接口的新实例。
+	if tag.Private {
+		return &InvalidFieldError{
+			Owner:  o.reflectType,
+			Field:  fieldName,
+			format: "found private inject tag on interface field %s in type %s",
 		}
+	}
+
+	// 命名注入总是在populateExplicit中处理，可选的或出错的命名字段保持为nil。
+	if tag.Name != "" {
+		return nil
+	}
+
+	// 不要覆盖现有值。
+	if !isNilOrZero(field, fieldType) {
+		return nil
+	}
+
+	if len(tag.Qualifiers) > 0 {
+		return g.populateQualified(o, i, tag)
+	}
 
-		// 命名注入必须已经在populateExplicit中处理。
-		if tag.Name != "" {
-			panic(fmt.Sprintf("unhandled named instance with name %s", tag.Name))
+	// 为字段找到一个且仅一个可分配的值，有多个时选择唯一的主要对象或不带限定符的对象。
+	candidate, ambiguous := pick(fieldType, g.assignable(fieldType))
+	if ambiguous != nil {
+		return &AmbiguousDependencyError{
+			Owner:      o.reflectType,
+			Field:      fieldName,
+			Type:       fieldType,
+			Candidates: ambiguous,
 		}
+	}
+	if candidate != nil {
+		existing, err := g.decorate(fieldType, candidate)
+		if err != nil {
+			return err
+		}
+		g.set(o, fieldName, field, reflect.ValueOf(existing.Value))
+		if g.Logger != nil {
+			g.Logger.Info("assigned existing %v to interface field %s in %v", existing, fieldName, o)
+		}
+		g.depend(o, fieldName, existing)
+		return nil
+	}
 
-		// 为字段找到一个且仅一个可分配的值。
-		var found *Object
-		for _, existing := range g.unnamed {
-			if existing.private {
-				continue
-			}
-			if existing.reflectType.AssignableTo(fieldType) {
-				if found != nil {
-					return fmt.Errorf(
-						"found two assignable values for field %s in type %s. one type %s with value %v and another type %s with value %v",
-						o.reflectType.Elem().Field(i).Name,
-						o.reflectType,
-						found.reflectType,
-						found.Value,
-						existing.reflectType,
-						existing.reflectValue,
-					)
-				}
-				found = existing
-				field.Set(reflect.ValueOf(existing.Value))
-				if g.Logger != nil {
-					g.Logger.Info("assigned existing %v to interface field %s in %v", existing, o.reflectType.Elem().Field(i).Name, o)
-				}
-				o.addDep(fieldName, existing)
-			}
+	// 没有现有实例时，尝试通过构造函数创建。
+	constructor, err := g.findConstructor(fieldType)
+	if err != nil {
+		return err
+	}
+	if constructor != nil {
+		if err := g.enter(o.reflectType, o.Name, fieldName); err != nil {
+			return err
 		}
-		if found == nil {
-			return fmt.Errorf("found no assignable value for field %s in type %s",
-				o.reflectType.Elem().Field(i).Name,
-				o.reflectType,
-			)
+		constructed, err := g.construct(constructor)
+		g.leave()
+		if err != nil {
+			return err
+		}
+		if constructed, err = g.decorate(fieldType, constructed); err != nil {
+			return err
+		}
+		g.set(o, fieldName, field, reflect.ValueOf(constructed.Value))
+		if g.Logger != nil {
+			g.Logger.Info("assigned constructed %v to interface field %s in %v", constructed, fieldName, o)
 		}
+		g.depend(o, fieldName, constructed)
+		return nil
 	}
 
-	return nil
+	if tag.Optional {
+		if g.Logger != nil {
+			g.Logger.Info("left optional field %s in %v unset: found no assignable value", fieldName, o)
+		}
+		return nil
+	}
+
+	return &MissingDependencyError{
+		Owner: o.reflectType,
+		Field: fieldName,
+		Type:  fieldType,
+	}
 }
 
 // Objects 返回所有已知对象，包括命名的和未命名的。返回的
@@ -553,11 +833,18 @@ var (
 )
 
 type tag struct {
-	Name    string
-	Inline  bool
-	Private bool
+	Name     string
+	Inline   bool
+	Private  bool
+	Named    bool // 切片注入时同时收集命名对象
+	Optional bool // 找不到依赖时保持字段为空而不是报错
+
+	Qualifiers map[string]string // 只注入带有所有这些限定符的对象
 }
 
+// parseTag 解析inject标签。标签值的第一部分是名称或inline、private关键字，
+// 之后可以跟随以逗号分隔的选项，例如 `inject:",named"` 或 `inject:"foo,optional"`。
+// 限定符选项qualifier=key=value可以出现在任何位置，例如 `inject:"qualifier=region=eu,optional"`。
 func parseTag(t string) (*tag, error) {
 	found, value, err := Extract("inject", t)
 	if err != nil {
@@ -566,16 +853,68 @@ func parseTag(t string) (*tag, error) {
 	if !found {
 		return nil, nil
 	}
-	if value == "" {
-		return injectOnly, nil
+	name, options, hasOptions := strings.Cut(value, ",")
+	if !hasOptions {
+		switch value {
+		case "":
+			return injectOnly, nil
+		case "inline":
+			return injectInline, nil
+		case "private":
+			return injectPrivate, nil
+		}
+		if !strings.HasPrefix(value, qualifierPrefix) {
+			return &tag{Name: value}, nil
+		}
 	}
-	if value == "inline" {
-		return injectInline, nil
+
+	parsed := &tag{}
+	switch {
+	case name == "inline":
+		parsed.Inline = true
+	case name == "private":
+		parsed.Private = true
+	case strings.HasPrefix(name, qualifierPrefix):
+		if err := parsed.addQualifier(name); err != nil {
+			return nil, err
+		}
+	default:
+		parsed.Name = name
 	}
-	if value == "private" {
-		return injectPrivate, nil
+	if hasOptions {
+		for _, option := range strings.Split(options, ",") {
+			switch {
+			case option == "named":
+				parsed.Named = true
+			case option == "optional":
+				parsed.Optional = true
+			case strings.HasPrefix(option, qualifierPrefix):
+				if err := parsed.addQualifier(option); err != nil {
+					return nil, err
+				}
+			default:
+				return nil, ErrInvalidTag
+			}
+		}
+	}
+	// 限定符只用于选择现有的对象，不能与名称或私有注入一起使用。
+	if len(parsed.Qualifiers) > 0 && (parsed.Name != "" || parsed.Private || parsed.Inline) {
+		return nil, ErrInvalidTag
 	}
-	return &tag{Name: value}, nil
+	return parsed, nil
+}
+
+// addQualifier 添加形如qualifier=key=value的限定符选项。
+func (t *tag) addQualifier(option string) error {
+	key, value, ok := parseQualifier(strings.TrimPrefix(option, qualifierPrefix))
+	if !ok {
+		return ErrInvalidTag
+	}
+	if t.Qualifiers == nil {
+		t.Qualifiers = make(map[string]string)
+	}
+	t.Qualifiers[key] = value
+	return nil
 }
 
 func isStructPtr(t reflect.Type) bool {
@@ -802,7 +802,7 @@ func TestInjectMap(t *testing.T) {
 }
 
 type TypeInjectWithMapWithoutPrivate struct {
-	A map[string]int `inject:""`
+	A map[int]int `inject:""`
 }
 
 func TestInjectMapWithoutPrivate(t *testing.T) {
@@ -812,7 +812,7 @@ func TestInjectMapWithoutPrivate(t *testing.T) {
 		t.Fatalf("expected error for %+v", v)
 	}
 
-	const msg = "inject on map field A in type *inject_test.TypeInjectWithMapWithoutPrivate must be named or private"
+	const msg = "inject on map field A in type *inject_test.TypeInjectWithMapWithoutPrivate must be keyed by string or private"
 	if err.Error() != msg {
 		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
 	}
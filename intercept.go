@@ -0,0 +1,153 @@
+package inject
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// InvocationHandler 代理将接口方法的调用转发给InvocationHandler，可变参数以切片的形式作为最后一个参数传入。
+type InvocationHandler func(method string, args []reflect.Value) []reflect.Value
+
+// Interceptor 拦截代理的方法调用，通常在调用inv.Proceed前后加入日志或追踪。
+type Interceptor func(inv Invocation) []reflect.Value
+
+// Invocation 一次被拦截的方法调用
+type Invocation struct {
+	Target interface{}     // 被代理的值
+	Type   reflect.Type    // 被代理的接口类型
+	Method string          // 被调用的方法名称
+	Args   []reflect.Value // 调用参数，拦截器可以在调用Proceed之前修改它
+	chain  []Interceptor
+	call   func(args []reflect.Value) []reflect.Value
+}
+
+// Proceed 调用拦截链中的下一个拦截器，最后调用目标的方法，返回方法的结果。
+func (inv Invocation) Proceed() []reflect.Value {
+	if len(inv.chain) == 0 {
+		return inv.call(inv.Args)
+	}
+	next := inv
+	next.chain = inv.chain[1:]
+	return inv.chain[0](next)
+}
+
+// Interception 拦截规则。Type不为nil时拦截注入到该接口类型的bean，
+// Name不为空时拦截该名称的bean。两者都给出时必须同时满足。
+type Interception struct {
+	Type         reflect.Type
+	Name         string
+	Interceptors []Interceptor
+}
+
+// proxies 所有已注册的代理工厂，map[reflect.Type]func(InvocationHandler) interface{}
+var proxies sync.Map
+
+// RegisterProxy 为接口类型T注册代理工厂。Go无法在运行时创建接口的实现，
+// 因此需要为每个被拦截的接口生成（或手写）一个将所有方法转发给InvocationHandler的代理类型，
+// 通常在生成代码的init函数中调用。
+func RegisterProxy[T any](factory func(h InvocationHandler) T) {
+	t := typeOf[T]()
+	if t.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("cannot register proxy for non-interface type %s", t))
+	}
+	proxies.Store(t, func(h InvocationHandler) interface{} {
+		return factory(h)
+	})
+}
+
+// Intercept 注册一些拦截规则，匹配的拦截器按注册顺序组成拦截链，先注册的在外层。
+func (g *Graph) Intercept(rules ...*Interception) error {
+	for _, rule := range rules {
+		if rule.Type == nil && rule.Name == "" {
+			return errors.New("interception must match an interface type or a bean name")
+		}
+		if rule.Type != nil && rule.Type.Kind() != reflect.Interface {
+			return fmt.Errorf("cannot intercept non-interface type %s", rule.Type)
+		}
+		g.interceptions = append(g.interceptions, rule)
+	}
+	return nil
+}
+
+// Intercept 为接口类型T注册拦截器，注入到T的bean会被替换为经过拦截链的代理，T必须通过RegisterProxy注册了代理。
+func Intercept[T any](c *Container, interceptors ...Interceptor) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("provide interceptors", StateConfiguring); err != nil {
+		return err
+	}
+	return c.graph.Intercept(&Interception{Type: typeOf[T](), Interceptors: interceptors})
+}
+
+// InterceptNamed 为指定名称的bean注册拦截器，该bean注入到接口字段时会被替换为代理
+func (c *Container) InterceptNamed(name string, interceptors ...Interceptor) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("provide interceptors", StateConfiguring); err != nil {
+		return err
+	}
+	return c.graph.Intercept(&Interception{Name: name, Interceptors: interceptors})
+}
+
+// interceptorsFor 返回注入到类型为t的位置时o匹配的所有拦截器，父级的拦截器在外层。
+func (g *Graph) interceptorsFor(t reflect.Type, o *Object) []Interceptor {
+	var found []Interceptor
+	if g.parent != nil {
+		found = g.parent.interceptorsFor(t, o)
+	}
+	for _, rule := range g.interceptions {
+		if rule.Type != nil && rule.Type != t {
+			continue
+		}
+		if rule.Name != "" && rule.Name != o.Name {
+			continue
+		}
+		found = append(found, rule.Interceptors...)
+	}
+	return found
+}
+
+// proxy 创建接口类型t的代理，将o的方法调用转发给拦截链。
+func (g *Graph) proxy(t reflect.Type, o *Object, interceptors []Interceptor) (*Object, error) {
+	factory, ok := proxies.Load(t)
+	if !ok {
+		return nil, fmt.Errorf("no proxy registered for interface %s intercepted on %v", t, o)
+	}
+
+	target := reflect.ValueOf(o.Value)
+	handler := func(method string, args []reflect.Value) []reflect.Value {
+		m := target.MethodByName(method)
+		if !m.IsValid() {
+			panic(fmt.Sprintf("proxy for %s called unknown method %s on %v", t, method, o))
+		}
+		return Invocation{
+			Target: o.Value,
+			Type:   t,
+			Method: method,
+			Args:   args,
+			chain:  interceptors,
+			call: func(args []reflect.Value) []reflect.Value {
+				if m.Type().IsVariadic() {
+					return m.CallSlice(args)
+				}
+				return m.Call(args)
+			},
+		}.Proceed()
+	}
+
+	proxied := &Object{
+		Value:     factory.(func(InvocationHandler) interface{})(handler),
+		created:   true,
+		private:   true,
+		decorated: true,
+	}
+	proxied.reflectType = reflect.TypeOf(proxied.Value)
+	proxied.reflectValue = reflect.ValueOf(proxied.Value)
+	if !proxied.reflectType.Implements(t) {
+		return nil, fmt.Errorf("proxy %s registered for interface %s does not implement it", proxied.reflectType, t)
+	}
+	proxied.addDep("target", o)
+	return proxied, nil
+}
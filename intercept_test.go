@@ -0,0 +1,208 @@
+package inject_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeForInterceptGreeter interface {
+	Greet(name string) string
+	Join(sep string, parts ...string) string
+}
+
+type TypeForInterceptHello struct {
+	Prefix string
+}
+
+func (h *TypeForInterceptHello) Greet(name string) string { return h.Prefix + " " + name }
+
+func (h *TypeForInterceptHello) Join(sep string, parts ...string) string {
+	return strings.Join(parts, sep)
+}
+
+// typeForInterceptGreeterProxy 模拟生成的代理
+type typeForInterceptGreeterProxy struct {
+	handler inject.InvocationHandler
+}
+
+func (p *typeForInterceptGreeterProxy) Greet(name string) string {
+	out := p.handler("Greet", []reflect.Value{reflect.ValueOf(name)})
+	return out[0].Interface().(string)
+}
+
+func (p *typeForInterceptGreeterProxy) Join(sep string, parts ...string) string {
+	out := p.handler("Join", []reflect.Value{reflect.ValueOf(sep), reflect.ValueOf(parts)})
+	return out[0].Interface().(string)
+}
+
+func init() {
+	inject.RegisterProxy(func(h inject.InvocationHandler) TypeForInterceptGreeter {
+		return &typeForInterceptGreeterProxy{handler: h}
+	})
+}
+
+type TypeForInterceptUser struct {
+	Greeter TypeForInterceptGreeter `inject:""`
+}
+
+func recordingInterceptor(name string, log *[]string) inject.Interceptor {
+	return func(inv inject.Invocation) []reflect.Value {
+		*log = append(*log, name+" before "+inv.Method)
+		out := inv.Proceed()
+		*log = append(*log, name+" after "+inv.Method)
+		return out
+	}
+}
+
+func TestInterceptType(t *testing.T) {
+	c := inject.NewContainer()
+	var user TypeForInterceptUser
+	hello := &TypeForInterceptHello{Prefix: "hello"}
+	if err := c.Provides(&user, hello); err != nil {
+		t.Fatal(err)
+	}
+	var log []string
+	upper := func(inv inject.Invocation) []reflect.Value {
+		if inv.Method == "Greet" {
+			inv.Args = []reflect.Value{reflect.ValueOf(strings.ToUpper(inv.Args[0].String()))}
+		}
+		return inv.Proceed()
+	}
+	err := inject.Intercept[TypeForInterceptGreeter](c, recordingInterceptor("outer", &log), recordingInterceptor("inner", &log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inject.Intercept[TypeForInterceptGreeter](c, upper); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Greeter == TypeForInterceptGreeter(hello) {
+		t.Fatal("expected a proxy to be injected")
+	}
+	if got := user.Greeter.Greet("bob"); got != "hello BOB" {
+		t.Fatalf("expected the interceptor to modify the arguments but got %s", got)
+	}
+	expected := []string{"outer before Greet", "inner before Greet", "inner after Greet", "outer after Greet"}
+	if strings.Join(log, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected:\n%v\nactual:\n%v", expected, log)
+	}
+	if got := user.Greeter.Join("-", "a", "b"); got != "a-b" {
+		t.Fatalf("expected variadic arguments to be forwarded but got %s", got)
+	}
+
+	resolved, err := inject.Resolve[TypeForInterceptGreeter](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != user.Greeter {
+		t.Fatal("expected Resolve to return the same proxy")
+	}
+}
+
+func TestInterceptNamed(t *testing.T) {
+	c := inject.NewContainer()
+	var user struct {
+		Formal TypeForInterceptGreeter `inject:"formal"`
+		Plain  TypeForInterceptGreeter `inject:""`
+	}
+	plain := &TypeForInterceptHello{Prefix: "hi"}
+	if err := c.Provides(&user, plain); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideWithName("formal", &TypeForInterceptHello{Prefix: "good day"}); err != nil {
+		t.Fatal(err)
+	}
+	var log []string
+	if err := c.InterceptNamed("formal", recordingInterceptor("formal", &log)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Formal.Greet("bob") != "good day bob" || len(log) != 2 {
+		t.Fatalf("expected the named bean to be intercepted but got %v", log)
+	}
+	if user.Plain != TypeForInterceptGreeter(plain) {
+		t.Fatal("expected other beans not to be intercepted")
+	}
+	resolved, err := inject.ResolveNamed[TypeForInterceptGreeter](c, "formal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != user.Formal {
+		t.Fatal("expected ResolveNamed to return the same proxy")
+	}
+}
+
+func TestInterceptCollections(t *testing.T) {
+	c := inject.NewContainer()
+	var user struct {
+		Greeters []TypeForInterceptGreeter          `inject:""`
+		Named    map[string]TypeForInterceptGreeter `inject:""`
+	}
+	if err := c.Provides(&user, &TypeForInterceptHello{Prefix: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideWithName("formal", &TypeForInterceptHello{Prefix: "good day"}); err != nil {
+		t.Fatal(err)
+	}
+	var log []string
+	if err := inject.Intercept[TypeForInterceptGreeter](c, recordingInterceptor("log", &log)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(user.Greeters) != 1 || user.Greeters[0].Greet("bob") != "hi bob" {
+		t.Fatalf("expected one greeter in the slice but got %v", user.Greeters)
+	}
+	if user.Named["formal"].Greet("bob") != "good day bob" {
+		t.Fatal("expected the named greeter in the map")
+	}
+	expected := []string{"log before Greet", "log after Greet", "log before Greet", "log after Greet"}
+	if strings.Join(log, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected:\n%v\nactual:\n%v", expected, log)
+	}
+}
+
+func TestInterceptWithoutProxy(t *testing.T) {
+	c := inject.NewContainer()
+	var user struct {
+		Answerable Answerable `inject:""`
+	}
+	if err := c.Provides(&user, &TypeAnswerStruct{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inject.Intercept[Answerable](c, func(inv inject.Invocation) []reflect.Value { return inv.Proceed() }); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Populate()
+	const msg = "no proxy registered for interface inject_test.Answerable intercepted on *inject_test.TypeAnswerStruct"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+}
+
+func TestInterceptInvalid(t *testing.T) {
+	var g inject.Graph
+	err := g.Intercept(&inject.Interception{Type: reflect.TypeOf(&TypeForInterceptHello{})})
+	const msg = "cannot intercept non-interface type *inject_test.TypeForInterceptHello"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected registering a proxy for a concrete type to panic")
+		}
+	}()
+	inject.RegisterProxy(func(h inject.InvocationHandler) *TypeForInterceptHello { return nil })
+}
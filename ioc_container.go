@@ -1,23 +1,128 @@
 package inject
 
 import (
+	"fmt"
+	"sync"
 	"time"
 )
 
-// Container IoC容器
+// ContainerState 容器所处的阶段
+type ContainerState int
+
+const (
+	StateConfiguring ContainerState = iota // 正在提供bean，尚未填充
+	StatePopulated                         // 已经填充，不能再提供bean
+	StateStarted                           // 已经启动
+	StateStopped                           // 已经停止，可以再次启动
+)
+
+func (s ContainerState) String() string {
+	switch s {
+	case StateConfiguring:
+		return "configuring"
+	case StatePopulated:
+		return "populated"
+	case StateStarted:
+		return "started"
+	case StateStopped:
+		return "stopped"
+	}
+	return fmt.Sprintf("ContainerState(%d)", int(s))
+}
+
+// Container IoC容器，可以被多个goroutine同时使用。
+// 容器在Populate之前接受新的bean，之后只能取出bean以及启动和停止。
 type Container struct {
-	graph Graph
+	mu        sync.Mutex // 保护graph和state
+	lifecycle sync.Mutex // 保证Start和Stop不会同时进行
+	graph     Graph
+	state     ContainerState
+	started   []*Object // 已启动的bean，按启动顺序排列，由mu保护
+	tracker   lifecycleTracker
+	parent    *Container
+
+	conditionals []conditional // 等待在Populate之前求值的条件注册
+	profiles     []string      // 通过SetProfiles设置的profile，为nil时读取环境变量
 }
 
 // NewContainer 创建一个新的IoC容器
 func NewContainer() *Container {
-	return &Container{
-		graph: Graph{},
+	c := &Container{}
+	c.graph.locker = containerLocker{c}
+	c.graph.deferInits = true
+	c.track()
+	return c
+}
+
+// NewChild 创建一个子容器。子容器中找不到的bean会在当前容器中查找，
+// 子容器可以提供与当前容器相同类型或名称的bean来覆盖它们。
+// 子容器的Start和Stop只管理子容器自己的bean。
+func (c *Container) NewChild() *Container {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	child := &Container{parent: c, profiles: c.profiles}
+	child.graph = Graph{
+		Logger:           c.graph.Logger,
+		CollectAllErrors: c.graph.CollectAllErrors,
+		parent:           &c.graph,
+		locker:           containerLocker{child},
 	}
+	child.track()
+	return child
+}
+
+// track 使依赖图在容器启动后把新创建的单例交给容器管理。
+func (c *Container) track() {
+	c.tracker.adopt = c.adopt
+	c.graph.tracker = &c.tracker
+}
+
+// lockAll 依次锁定容器及其所有祖先，因为查找可能会访问祖先的依赖图。
+// 总是先锁定子容器，所以不会与祖先自己的操作死锁。
+func (c *Container) lockAll() {
+	for ; c != nil; c = c.parent {
+		c.mu.Lock()
+	}
+}
+
+func (c *Container) unlockAll() {
+	for ; c != nil; c = c.parent {
+		c.mu.Unlock()
+	}
+}
+
+// containerLocker 使依赖图在Lazy和Provider查找依赖时锁定容器。
+type containerLocker struct {
+	c *Container
+}
+
+func (l containerLocker) Lock()   { l.c.lockAll() }
+func (l containerLocker) Unlock() { l.c.unlockAll() }
+
+// State 返回容器当前所处的阶段
+func (c *Container) State() ContainerState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// expect 检查容器是否处于给定的阶段之一，调用方必须持有c.mu。
+func (c *Container) expect(action string, states ...ContainerState) error {
+	for _, s := range states {
+		if c.state == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot %s when the container is %s", action, c.state)
 }
 
 // Provides 使用默认名称提供一些bean
 func (c *Container) Provides(beans ...interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("provide beans", StateConfiguring); err != nil {
+		return err
+	}
 	for _, bean := range beans {
 		if err := c.graph.Provide(&Object{Value: bean}); err != nil {
 			return err
@@ -28,17 +133,180 @@ func (c *Container) Provides(beans ...interface{}) error {
 
 // ProvideWithName 使用指定名称提供bean
 func (c *Container) ProvideWithName(name string, bean interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("provide beans", StateConfiguring); err != nil {
+		return err
+	}
 	return c.graph.Provide(&Object{Name: name, Value: bean})
 }
 
 // Populate 为所有bean填充依赖字段。
 // 此函数必须在提供所有bean后调用
 func (c *Container) Populate() error {
-	start := time.Now()
-	defer func() {
-		if c.graph.Logger != nil {
-			c.graph.Logger.Info("populate the bean container toke time %s", time.Now().Sub(start))
+	return c.graph.locked(func() error {
+		if err := c.expect("populate", StateConfiguring); err != nil {
+			return err
+		}
+		if err := c.evaluateConditions(); err != nil {
+			return err
+		}
+
+		start := time.Now()
+		defer func() {
+			if c.graph.Logger != nil {
+				c.graph.Logger.Info("populate the bean container toke time %s", time.Now().Sub(start))
+			}
+		}()
+		if err := c.graph.Populate(); err != nil {
+			return err
+		}
+		c.state = StatePopulated
+		return nil
+	})
+}
+
+// Plan 计算Populate将要进行的所有注入，而不修改任何bean
+func (c *Container) Plan() (*Plan, error) {
+	var p *Plan
+	err := c.graph.locked(func() error {
+		if err := c.expect("plan", StateConfiguring); err != nil {
+			return err
+		}
+		if err := c.evaluateConditions(); err != nil {
+			return err
+		}
+		var err error
+		p, err = c.graph.Plan()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Apply 提交Plan计算出的注入计划
+func (c *Container) Apply(p *Plan) error {
+	return c.graph.locked(func() error {
+		if err := c.expect("apply a plan", StateConfiguring); err != nil {
+			return err
 		}
-	}()
-	return c.graph.Populate()
+		if err := c.graph.Apply(p); err != nil {
+			return err
+		}
+		c.state = StatePopulated
+		return nil
+	})
+}
+
+// ProvideConstructor 提供一个构造函数，形如 func(A, B, ...) (T, error)。
+// 构造函数在T第一次被需要时才会调用，paramNames可选地按位置为参数指定注入的对象名称。
+// 构造函数在容器持有锁时调用，不能在其中调用Resolve等容器方法，需要的依赖应声明为参数。
+func (c *Container) ProvideConstructor(fn interface{}, paramNames ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("provide constructors", StateConfiguring); err != nil {
+		return err
+	}
+	return c.graph.ProvideConstructor(&Constructor{Func: fn, Params: paramNames})
+}
+
+// ProvidePrimary 提供一些主要的bean，有多个bean可以分配给同一个接口时优先选择它们
+func (c *Container) ProvidePrimary(beans ...interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("provide beans", StateConfiguring); err != nil {
+		return err
+	}
+	for _, bean := range beans {
+		if err := c.graph.Provide(&Object{Value: bean, Primary: true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProvideQualified 提供一个带有限定符的bean，限定符形如 "region=eu"。
+// 带有限定符的同类型bean可以共存，通过 `inject:"qualifier=region=eu"` 选择。
+func (c *Container) ProvideQualified(bean interface{}, qualifiers ...string) error {
+	parsed, err := parseQualifiers(qualifiers)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("provide beans", StateConfiguring); err != nil {
+		return err
+	}
+	return c.graph.Provide(&Object{Value: bean, Qualifiers: parsed})
+}
+
+// Install 安装模块及其导入的所有模块，同一个模块只会安装一次
+func (c *Container) Install(modules ...*Module) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("install modules", StateConfiguring); err != nil {
+		return err
+	}
+	return c.graph.Install(modules...)
+}
+
+// ProvidePrototype 以Prototype作用域提供一些bean类型，每个注入点和每次Resolve都会得到一个新的实例。
+// bean只用于确定类型，可以是nil指针，例如 (*Session)(nil)。
+func (c *Container) ProvidePrototype(beans ...interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("provide beans", StateConfiguring); err != nil {
+		return err
+	}
+	for _, bean := range beans {
+		if err := c.graph.Provide(&Object{Value: bean, Scope: Prototype}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProvidePrototypeConstructor 提供一个Prototype作用域的构造函数，每次需要T时都会调用它。
+func (c *Container) ProvidePrototypeConstructor(fn interface{}, paramNames ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("provide constructors", StateConfiguring); err != nil {
+		return err
+	}
+	return c.graph.ProvideConstructor(&Constructor{Func: fn, Params: paramNames, Scope: Prototype})
+}
+
+// ProvideRequestScoped 以Request作用域提供一些bean类型，每个请求作用域中都会创建一个新的实例。
+// bean只用于确定类型，可以是nil指针，例如 (*Transaction)(nil)。
+func (c *Container) ProvideRequestScoped(beans ...interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("provide beans", StateConfiguring); err != nil {
+		return err
+	}
+	for _, bean := range beans {
+		if err := c.graph.Provide(&Object{Value: bean, Scope: Request}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProvideRequestConstructor 提供一个Request作用域的构造函数，每个请求作用域中第一次需要T时调用它。
+func (c *Container) ProvideRequestConstructor(fn interface{}, paramNames ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.expect("provide constructors", StateConfiguring); err != nil {
+		return err
+	}
+	return c.graph.ProvideConstructor(&Constructor{Func: fn, Params: paramNames, Scope: Request})
+}
+
+// AddPropertySource 添加value标签使用的配置来源，先添加的来源优先
+func (c *Container) AddPropertySource(sources ...PropertySource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.graph.Properties = append(c.graph.Properties, sources...)
 }
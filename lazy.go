@@ -0,0 +1,154 @@
+package inject
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Lazy 延迟注入的依赖。Populate时只记录如何查找T，第一次调用Get时才查找或创建T并完成注入，
+// 之后总是返回同一个结果。Get可以被多个goroutine同时调用，但不能在Populate或Resolve的过程中调用，
+// 例如在构造函数中。延迟注入也可以用来打破构造时的循环依赖。
+type Lazy[T any] struct {
+	once    sync.Once
+	resolve func() (interface{}, error)
+	value   T
+	err     error
+}
+
+// Get 返回延迟注入的依赖，只在第一次调用时查找。
+func (l *Lazy[T]) Get() (T, error) {
+	if l.resolve == nil {
+		var zero T
+		return zero, errors.New("lazy dependency was not injected")
+	}
+	l.once.Do(func() {
+		v, err := l.resolve()
+		if err != nil {
+			l.err = err
+			return
+		}
+		if v != nil {
+			l.value = v.(T)
+		}
+	})
+	return l.value, l.err
+}
+
+func (l *Lazy[T]) target() reflect.Type {
+	return typeOf[T]()
+}
+
+func (l *Lazy[T]) bind(resolve func() (interface{}, error)) {
+	l.resolve = resolve
+}
+
+// Provider 每次调用Get都重新查找T的依赖。T是Prototype作用域时每次都会得到一个新的实例，
+// 否则总是得到同一个单例。与Lazy一样，Get不能在Populate或Resolve的过程中调用。
+type Provider[T any] struct {
+	resolve func() (interface{}, error)
+}
+
+// Get 查找并返回T的实例。
+func (p Provider[T]) Get() (T, error) {
+	var zero T
+	if p.resolve == nil {
+		return zero, errors.New("provider was not injected")
+	}
+	v, err := p.resolve()
+	if err != nil || v == nil {
+		return zero, err
+	}
+	return v.(T), nil
+}
+
+func (p *Provider[T]) target() reflect.Type {
+	return typeOf[T]()
+}
+
+func (p *Provider[T]) bind(resolve func() (interface{}, error)) {
+	p.resolve = resolve
+}
+
+// deferred 由*Lazy和*Provider实现，使populateExplicit能够识别它们。
+type deferred interface {
+	target() reflect.Type
+	bind(resolve func() (interface{}, error))
+}
+
+var deferredType = reflect.TypeOf((*deferred)(nil)).Elem()
+
+// populateDeferred 为o的第i个Lazy或Provider字段绑定查找函数，而不立即查找依赖。
+func (g *Graph) populateDeferred(o *Object, i int, tag *tag) error {
+	info := &o.structInfo().fields[i]
+	field := o.reflectValue.Elem().Field(i)
+
+	if tag.Private {
+		return &InvalidFieldError{
+			Owner:  o.reflectType,
+			Field:  info.name,
+			format: "found private inject tag on lazy field %s in type %s",
+		}
+	}
+
+	// 不要覆盖现有值。
+	if !isNilOrZero(field, info.typ) {
+		return nil
+	}
+
+	value := reflect.New(info.typ)
+	d := value.Interface().(deferred)
+	t := d.target()
+	if tag.Name == "" && !isStructPtr(t) && t.Kind() != reflect.Interface {
+		return &InvalidFieldError{
+			Owner:  o.reflectType,
+			Field:  info.name,
+			format: "lazy field %s in type %s must refer to a pointer to a struct or an interface",
+		}
+	}
+	d.bind(func() (interface{}, error) {
+		return g.resolveDeferred(o, info.name, t, tag)
+	})
+	g.set(o, info.name, field, value.Elem())
+	if g.Logger != nil {
+		g.Logger.Info("bound lazy field %s in %v", info.name, o)
+	}
+	return nil
+}
+
+// resolveDeferred 在Get时为o的字段field查找依赖。
+func (g *Graph) resolveDeferred(o *Object, field string, t reflect.Type, tag *tag) (interface{}, error) {
+	var dep *Object
+	err := g.locked(func() error {
+		var err error
+		if tag.Name != "" {
+			dep, err = g.resolveNamed(tag.Name, t)
+			return err
+		}
+		n := len(g.unnamed)
+		if isStructPtr(t) {
+			dep, err = g.lookupOrCreate(t)
+		} else {
+			dep, err = g.resolve(t)
+		}
+		if err == nil {
+			err = g.populateInterfaces(n)
+		}
+		if err == nil {
+			err = g.collectedErrors()
+		}
+		if err == nil {
+			o.addDep(field, dep)
+		}
+		return err
+	})
+	if err != nil {
+		var missing *MissingDependencyError
+		if tag.Optional && errors.As(err, &missing) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to resolve lazy field %s in %v: %w", field, o, err)
+	}
+	return dep.Value, nil
+}
@@ -0,0 +1,207 @@
+package inject_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeForLazyClient struct {
+	Answer *TypeAnswerStruct `inject:""`
+}
+
+type TypeForLazyUser struct {
+	Client inject.Lazy[*TypeForLazyClient] `inject:""`
+}
+
+func TestLazy(t *testing.T) {
+	c := inject.NewContainer()
+	calls := 0
+	err := c.ProvideConstructor(func() *TypeForLazyClient {
+		calls++
+		return &TypeForLazyClient{}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var user TypeForLazyUser
+	answer := &TypeAnswerStruct{}
+	if err := c.Provides(&user, answer); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 {
+		t.Fatal("expected the client not to be created during populate")
+	}
+
+	var wg sync.WaitGroup
+	clients := make([]*TypeForLazyClient, 8)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client, err := user.Client.Get()
+			if err != nil {
+				t.Error(err)
+			}
+			clients[i] = client
+		}(i)
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("expected the client to be created once but it was created %d times", calls)
+	}
+	for _, client := range clients {
+		if client != clients[0] {
+			t.Fatal("expected every Get to return the same client")
+		}
+	}
+	if clients[0].Answer != answer {
+		t.Fatal("expected the lazy client to be populated")
+	}
+	resolved, err := inject.Resolve[*TypeForLazyClient](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != clients[0] {
+		t.Fatal("expected the lazy client to be shared with the container")
+	}
+}
+
+type TypeForLazyStub struct {
+	Name string
+}
+
+type TypeForLazyProviderUser struct {
+	Stubs   inject.Provider[*TypeForLazyStub]    `inject:""`
+	Answers inject.Provider[*TypeAnswerStruct]   `inject:""`
+	Named   inject.Lazy[string]                  `inject:"name"`
+	Missing inject.Lazy[*TypeForLazyClient]      `inject:"missing,optional"`
+	Iface   inject.Provider[TypeForScopeGreeter] `inject:""`
+}
+
+func TestProvider(t *testing.T) {
+	c := inject.NewContainer()
+	if err := c.ProvidePrototype((*TypeForLazyStub)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	var user TypeForLazyProviderUser
+	if err := c.Provides(&user, &TypeForScopeHello{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideWithName("name", "lazy"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := user.Stubs.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := user.Stubs.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Fatal("expected a new prototype instance for every Get")
+	}
+
+	answer, err := user.Answers.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := user.Answers.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer != again {
+		t.Fatal("expected the same singleton for every Get")
+	}
+
+	if name, err := user.Named.Get(); err != nil || name != "lazy" {
+		t.Fatalf("expected the named value but got %q, %v", name, err)
+	}
+	if missing, err := user.Missing.Get(); err != nil || missing != nil {
+		t.Fatalf("expected an optional missing value to be nil but got %v, %v", missing, err)
+	}
+	if greeter, err := user.Iface.Get(); err != nil || greeter.Greet() != "hello" {
+		t.Fatalf("expected the greeter but got %v, %v", greeter, err)
+	}
+}
+
+type TypeForLazyCycleA struct {
+	B *TypeForLazyCycleB
+}
+
+type TypeForLazyCycleB struct {
+	A inject.Lazy[*TypeForLazyCycleA] `inject:""`
+}
+
+func TestLazyBreaksCycle(t *testing.T) {
+	c := inject.NewContainer()
+	err := c.ProvideConstructor(func(b *TypeForLazyCycleB) *TypeForLazyCycleA {
+		return &TypeForLazyCycleA{B: b}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var user struct {
+		A *TypeForLazyCycleA `inject:""`
+	}
+	if err := c.Provides(&user); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := user.A.B.A.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != user.A {
+		t.Fatal("expected the lazy field to refer back to the constructed value")
+	}
+}
+
+func TestLazyNotInjected(t *testing.T) {
+	var lazy inject.Lazy[*TypeForLazyClient]
+	_, err := lazy.Get()
+	const msg = "lazy dependency was not injected"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+}
+
+func TestLazyInvalidTarget(t *testing.T) {
+	var user struct {
+		Value inject.Lazy[string] `inject:""`
+	}
+	err := inject.Populate(&user)
+	const msg = "lazy field Value in type *struct { Value inject.Lazy[string] \"inject:\\\"\\\"\" } must refer to a pointer to a struct or an interface"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+}
+
+func TestLazyMissingDependency(t *testing.T) {
+	var user TypeForLazyProviderUser
+	g := inject.Graph{}
+	if err := g.Provide(&inject.Object{Value: &user}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := user.Named.Get()
+	const msg = "failed to resolve lazy field Named in *inject_test.TypeForLazyProviderUser: did not find object named name"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+}
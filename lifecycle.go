@@ -0,0 +1,248 @@
+package inject
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Initializer 由需要在启动前初始化的bean实现
+type Initializer interface {
+	Init(ctx context.Context) error
+}
+
+// Starter 由需要在容器启动时启动的bean实现
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper 由需要在容器停止时释放资源的bean实现
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// Start 按依赖顺序（被依赖的bean在前）先调用所有bean的Init，再调用Start。
+// 如果某个bean启动失败，已经启动的bean会按相反的顺序被停止。
+// 此函数必须在Populate之后调用，bean的Init和Start中可以通过Resolve取出其他bean。
+// 此后由构造函数、Resolve或Lazy新创建的单例会在创建后立即调用Init和Start，并在Stop时被停止。
+func (c *Container) Start(ctx context.Context) error {
+	c.lifecycle.Lock()
+	defer c.lifecycle.Unlock()
+
+	c.mu.Lock()
+	if err := c.expect("start", StatePopulated, StateStopped); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	objects := c.graph.dependencyOrder()
+	c.tracker.running = true
+	c.tracker.seq = c.graph.seq
+	c.mu.Unlock()
+
+	for _, o := range objects {
+		if i, ok := o.Value.(Initializer); ok {
+			if err := i.Init(ctx); err != nil {
+				c.mu.Lock()
+				started := c.halt()
+				c.mu.Unlock()
+				if stopErr := c.stopAll(ctx, started); stopErr != nil && c.graph.Logger != nil {
+					c.graph.Logger.Info("failed to roll back start: %v", stopErr)
+				}
+				return fmt.Errorf("failed to init %v: %w", o, err)
+			}
+			if c.graph.Logger != nil {
+				c.graph.Logger.Info("initialized %v", o)
+			}
+		}
+	}
+
+	c.mu.Lock()
+	c.state = StateStarted
+	c.mu.Unlock()
+	for _, o := range objects {
+		if s, ok := o.Value.(Starter); ok {
+			if err := s.Start(ctx); err != nil {
+				err = fmt.Errorf("failed to start %v: %w", o, err)
+				if stopErr := c.stop(ctx); stopErr != nil && c.graph.Logger != nil {
+					c.graph.Logger.Info("failed to roll back start: %v", stopErr)
+				}
+				return err
+			}
+			if c.graph.Logger != nil {
+				c.graph.Logger.Info("started %v", o)
+			}
+		}
+		c.mu.Lock()
+		c.started = append(c.started, o)
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// Stop 按与启动相反的顺序调用已启动bean的Stop。
+// 即使某个bean停止失败，其余的bean仍会被停止，返回遇到的第一个错误。
+// 对已经停止的容器调用Stop不会做任何事情。
+func (c *Container) Stop(ctx context.Context) error {
+	c.lifecycle.Lock()
+	defer c.lifecycle.Unlock()
+
+	c.mu.Lock()
+	if c.state == StateStopped {
+		c.mu.Unlock()
+		return nil
+	}
+	if err := c.expect("stop", StateStarted); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	c.mu.Unlock()
+	return c.stop(ctx)
+}
+
+// stop 停止所有已启动的bean，调用方必须持有c.lifecycle。
+func (c *Container) stop(ctx context.Context) error {
+	c.mu.Lock()
+	c.state = StateStopped
+	started := c.halt()
+	c.mu.Unlock()
+	return c.stopAll(ctx, started)
+}
+
+// halt 停止跟踪新创建的单例，并取出所有已启动的bean，调用方必须持有c.mu。
+func (c *Container) halt() []*Object {
+	c.tracker.running = false
+	started := c.started
+	c.started = nil
+	return started
+}
+
+// stopAll 按相反的顺序调用objects的Stop，返回遇到的第一个错误。
+func (c *Container) stopAll(ctx context.Context, objects []*Object) error {
+	var first error
+	for i := len(objects) - 1; i >= 0; i-- {
+		o := objects[i]
+		s, ok := o.Value.(Stopper)
+		if !ok {
+			continue
+		}
+		if err := s.Stop(ctx); err != nil {
+			if first == nil {
+				first = fmt.Errorf("failed to stop %v: %w", o, err)
+			}
+			continue
+		}
+		if c.graph.Logger != nil {
+			c.graph.Logger.Info("stopped %v", o)
+		}
+	}
+	return first
+}
+
+// lifecycleTracker 跟踪容器启动之后依赖图中新创建的单例，由容器的c.mu保护。
+type lifecycleTracker struct {
+	running bool                  // 容器是否正在启动或已经启动
+	seq     int                   // 已经加入生命周期的对象中最大的提供顺序
+	adopt   func([]*Object) error // 为新创建的单例调用Init和Start
+}
+
+// fresh 按依赖顺序返回容器启动后g中新创建的单例，调用方必须持有容器的锁。
+func (g *Graph) fresh() []*Object {
+	t := g.tracker
+	if t == nil || !t.running || g.seq == t.seq {
+		return nil
+	}
+	var objects []*Object
+	for _, o := range g.dependencyOrder() {
+		if o.seq > t.seq {
+			objects = append(objects, o)
+		}
+	}
+	t.seq = g.seq
+	return objects
+}
+
+// adopt 为容器启动后新创建的单例调用Init和Start，并把它们加入已启动的bean。
+// 如果容器在此期间已经停止，它们会被立即停止。
+func (c *Container) adopt(objects []*Object) error {
+	ctx := context.Background()
+	for _, o := range objects {
+		if i, ok := o.Value.(Initializer); ok {
+			if err := i.Init(ctx); err != nil {
+				return fmt.Errorf("failed to init %v: %w", o, err)
+			}
+			if c.graph.Logger != nil {
+				c.graph.Logger.Info("initialized %v", o)
+			}
+		}
+		if s, ok := o.Value.(Starter); ok {
+			if err := s.Start(ctx); err != nil {
+				return fmt.Errorf("failed to start %v: %w", o, err)
+			}
+			if c.graph.Logger != nil {
+				c.graph.Logger.Info("started %v", o)
+			}
+		}
+
+		c.mu.Lock()
+		running := c.tracker.running
+		if running {
+			c.started = append(c.started, o)
+		}
+		c.mu.Unlock()
+		if !running {
+			if err := c.stopAll(ctx, []*Object{o}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dependencyOrder 返回所有非嵌入对象的拓扑排序，依赖总是排在依赖它的对象之前。
+// 依赖关系来自Object.Fields；循环依赖中的对象按提供顺序排列。
+// 原型和请求作用域的实例在创建时已经初始化，不参与生命周期；父级中的对象由父级管理，也不包括在内。
+func (g *Graph) dependencyOrder() []*Object {
+	objects := make([]*Object, 0, len(g.unnamed)+len(g.named))
+	for _, o := range g.unnamed {
+		if !o.embedded && o.Scope == Singleton {
+			objects = append(objects, o)
+		}
+	}
+	for _, o := range g.named {
+		if !o.embedded {
+			objects = append(objects, o)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].seq < objects[j].seq
+	})
+
+	visited := make(map[*Object]bool, len(objects))
+	ordered := make([]*Object, 0, len(objects))
+	var visit func(o *Object)
+	visit = func(o *Object) {
+		// 装饰器包装后的值不在依赖图中，但它所依赖的对象仍需排在依赖它的对象之前。
+		if visited[o] || o.Scope != Singleton || (!o.decorated && !g.owns(o)) {
+			return
+		}
+		visited[o] = true
+
+		deps := make([]*Object, 0, len(o.Fields))
+		for _, dep := range o.Fields {
+			deps = append(deps, dep)
+		}
+		sort.Slice(deps, func(i, j int) bool {
+			return deps[i].seq < deps[j].seq
+		})
+		for _, dep := range deps {
+			visit(dep)
+		}
+		if !o.decorated {
+			ordered = append(ordered, o)
+		}
+	}
+	for _, o := range objects {
+		visit(o)
+	}
+	return ordered
+}
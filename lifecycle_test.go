@@ -0,0 +1,258 @@
+package inject_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type lifecycleRecorder struct {
+	events []string
+}
+
+func (r *lifecycleRecorder) record(event string) {
+	r.events = append(r.events, event)
+}
+
+type TypeForLifecycleDB struct {
+	Recorder *lifecycleRecorder
+	FailStop bool
+}
+
+func (d *TypeForLifecycleDB) Init(ctx context.Context) error {
+	d.Recorder.record("init db")
+	return nil
+}
+
+func (d *TypeForLifecycleDB) Start(ctx context.Context) error {
+	d.Recorder.record("start db")
+	return nil
+}
+
+func (d *TypeForLifecycleDB) Stop(ctx context.Context) error {
+	d.Recorder.record("stop db")
+	if d.FailStop {
+		return errors.New("stop failed")
+	}
+	return nil
+}
+
+type TypeForLifecycleRepo struct {
+	DB       *TypeForLifecycleDB `inject:""`
+	Recorder *lifecycleRecorder
+}
+
+func (r *TypeForLifecycleRepo) Init(ctx context.Context) error {
+	r.Recorder.record("init repo")
+	return nil
+}
+
+func (r *TypeForLifecycleRepo) Stop(ctx context.Context) error {
+	r.Recorder.record("stop repo")
+	return nil
+}
+
+type TypeForLifecycleServer struct {
+	Repo     *TypeForLifecycleRepo `inject:""`
+	Recorder *lifecycleRecorder
+	FailWith error
+}
+
+func (s *TypeForLifecycleServer) Start(ctx context.Context) error {
+	s.Recorder.record("start server")
+	return s.FailWith
+}
+
+func (s *TypeForLifecycleServer) Stop(ctx context.Context) error {
+	s.Recorder.record("stop server")
+	return nil
+}
+
+func newLifecycleContainer(t *testing.T, r *lifecycleRecorder, failStart error, failStop bool) *inject.Container {
+	c := inject.NewContainer()
+	// 故意以与依赖相反的顺序提供
+	err := c.Provides(
+		&TypeForLifecycleServer{Recorder: r, FailWith: failStart},
+		&TypeForLifecycleRepo{Recorder: r},
+		&TypeForLifecycleDB{Recorder: r, FailStop: failStop},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestLifecycleOrder(t *testing.T) {
+	var r lifecycleRecorder
+	c := newLifecycleContainer(t, &r, nil, false)
+
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"init db",
+		"init repo",
+		"start db",
+		"start server",
+		"stop server",
+		"stop repo",
+		"stop db",
+	}
+	if !reflect.DeepEqual(r.events, expected) {
+		t.Fatalf("expected:\n%v\nactual:\n%v", expected, r.events)
+	}
+}
+
+func TestLifecycleStartRollback(t *testing.T) {
+	var r lifecycleRecorder
+	boom := errors.New("boom")
+	c := newLifecycleContainer(t, &r, boom, false)
+
+	err := c.Start(context.Background())
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected boom but got %v", err)
+	}
+
+	const msg = "failed to start *inject_test.TypeForLifecycleServer: boom"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+
+	expected := []string{
+		"init db",
+		"init repo",
+		"start db",
+		"start server",
+		"stop repo",
+		"stop db",
+	}
+	if !reflect.DeepEqual(r.events, expected) {
+		t.Fatalf("expected:\n%v\nactual:\n%v", expected, r.events)
+	}
+
+	// 回滚之后再次停止不应重复调用Stop
+	if err := c.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.events) != len(expected) {
+		t.Fatalf("unexpected events after second stop: %v", r.events)
+	}
+}
+
+func TestLifecycleStopContinuesOnError(t *testing.T) {
+	var r lifecycleRecorder
+	c := newLifecycleContainer(t, &r, nil, true)
+
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Stop(ctx)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "failed to stop *inject_test.TypeForLifecycleDB: stop failed"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+	if r.events[len(r.events)-1] != "stop db" {
+		t.Fatalf("expected db to be stopped last but got %v", r.events)
+	}
+}
+
+type TypeForLifecycleInitFailure struct{}
+
+func (TypeForLifecycleInitFailure) Init(ctx context.Context) error {
+	return errors.New("bad config")
+}
+
+func TestLifecycleInitFailure(t *testing.T) {
+	c := inject.NewContainer()
+	if err := c.Provides(&TypeForLifecycleInitFailure{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := c.Start(context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "failed to init *inject_test.TypeForLifecycleInitFailure: bad config"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+type TypeForLifecycleLate struct {
+	DB       *TypeForLifecycleDB `inject:""`
+	Recorder *lifecycleRecorder
+}
+
+func (l *TypeForLifecycleLate) Init(ctx context.Context) error {
+	l.Recorder.record("init late")
+	return nil
+}
+
+func (l *TypeForLifecycleLate) Start(ctx context.Context) error {
+	l.Recorder.record("start late")
+	return nil
+}
+
+func (l *TypeForLifecycleLate) Stop(ctx context.Context) error {
+	l.Recorder.record("stop late")
+	return nil
+}
+
+func TestLifecycleBuiltAfterStart(t *testing.T) {
+	r := &lifecycleRecorder{}
+	c := inject.NewContainer()
+	if err := c.Provides(&TypeForLifecycleDB{Recorder: r}); err != nil {
+		t.Fatal(err)
+	}
+	err := c.ProvideConstructor(func() *TypeForLifecycleLate {
+		return &TypeForLifecycleLate{Recorder: r}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	late, err := inject.Resolve[*TypeForLifecycleLate](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if late.DB == nil {
+		t.Fatal("expected the late bean to be populated")
+	}
+	if _, err := inject.Resolve[*TypeForLifecycleLate](c); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"init db", "start db", "init late", "start late", "stop late", "stop db"}
+	if !reflect.DeepEqual(r.events, expected) {
+		t.Fatalf("expected:\n%v\nactual:\n%v", expected, r.events)
+	}
+}
@@ -0,0 +1,66 @@
+package inject
+
+import (
+	"reflect"
+	"sync"
+)
+
+// structInfo 结构体类型的元数据，每个类型只解析一次，在所有依赖图之间共享。
+type structInfo struct {
+	fields []fieldInfo
+}
+
+// fieldInfo 结构体字段的元数据
+type fieldInfo struct {
+	name      string
+	typ       reflect.Type
+	kind      reflect.Kind
+	structPtr bool // 字段类型是否为结构体指针
+	deferred  bool // 字段类型是否为Lazy或Provider
+	anonymous bool
+	exported  bool // 字段是否可以被设置
+	rawTag    string
+
+	tag    *tag // inject标签，没有时为nil
+	tagErr error
+
+	value      bool // 是否有value标签
+	valueKey   string
+	valueDef   string
+	hasDefault bool
+	valueErr   error
+}
+
+// structInfoCache 缓存所有已解析的结构体类型，map[reflect.Type]*structInfo
+var structInfoCache sync.Map
+
+// structInfoOf 返回结构体类型t的元数据。
+func structInfoOf(t reflect.Type) *structInfo {
+	if info, ok := structInfoCache.Load(t); ok {
+		return info.(*structInfo)
+	}
+
+	info := &structInfo{fields: make([]fieldInfo, t.NumField())}
+	for i := range info.fields {
+		structField := t.Field(i)
+		f := &info.fields[i]
+		f.name = structField.Name
+		f.typ = structField.Type
+		f.kind = structField.Type.Kind()
+		f.structPtr = isStructPtr(structField.Type)
+		f.deferred = reflect.PointerTo(structField.Type).Implements(deferredType)
+		f.anonymous = structField.Anonymous
+		f.exported = structField.IsExported()
+		f.rawTag = string(structField.Tag)
+		f.tag, f.tagErr = parseTag(f.rawTag)
+		f.value, f.valueKey, f.valueDef, f.hasDefault, f.valueErr = parseValueTag(f.rawTag)
+	}
+
+	actual, _ := structInfoCache.LoadOrStore(t, info)
+	return actual.(*structInfo)
+}
+
+// structInfo 返回对象o所指向的结构体的元数据。
+func (o *Object) structInfo() *structInfo {
+	return structInfoOf(o.reflectType.Elem())
+}
@@ -0,0 +1,128 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type metadataDep struct{}
+
+type metadataTarget struct {
+	A       *metadataDep        `inject:""`
+	B       benchmarkAnswerable `inject:",optional"`
+	C       map[string]int      `inject:"private"`
+	Port    int                 `value:"port:80"`
+	Bad     int                 `inject:",bogus"`
+	plain   int
+	Ignored string
+}
+
+func TestStructInfoMatchesTags(t *testing.T) {
+	typ := reflect.TypeOf(metadataTarget{})
+	info := structInfoOf(typ)
+	if len(info.fields) != typ.NumField() {
+		t.Fatalf("expected %d fields but got %d", typ.NumField(), len(info.fields))
+	}
+	for i, f := range info.fields {
+		structField := typ.Field(i)
+		tag, tagErr := parseTag(string(structField.Tag))
+		found, key, def, hasDefault, valueErr := parseValueTag(string(structField.Tag))
+		if f.name != structField.Name || f.typ != structField.Type || f.kind != structField.Type.Kind() {
+			t.Fatalf("unexpected metadata %+v for field %s", f, structField.Name)
+		}
+		if !reflect.DeepEqual(f.tag, tag) || (f.tagErr == nil) != (tagErr == nil) {
+			t.Fatalf("unexpected tag %+v for field %s", f.tag, structField.Name)
+		}
+		if f.value != found || f.valueKey != key || f.valueDef != def || f.hasDefault != hasDefault ||
+			(f.valueErr == nil) != (valueErr == nil) {
+			t.Fatalf("unexpected value tag %+v for field %s", f, structField.Name)
+		}
+		if f.exported != reflect.ValueOf(&metadataTarget{}).Elem().Field(i).CanSet() {
+			t.Fatalf("unexpected exported %v for field %s", f.exported, structField.Name)
+		}
+	}
+	if structInfoOf(typ) != info {
+		t.Fatal("expected the metadata to be cached")
+	}
+}
+
+func TestStructInfoConcurrent(t *testing.T) {
+	typ := reflect.StructOf([]reflect.StructField{{
+		Name: "A",
+		Type: reflect.TypeOf(&metadataDep{}),
+		Tag:  `inject:""`,
+	}})
+	infos := make([]*structInfo, 10)
+	var wg sync.WaitGroup
+	for i := range infos {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			infos[i] = structInfoOf(typ)
+		}(i)
+	}
+	wg.Wait()
+	for _, info := range infos {
+		if info != infos[0] {
+			t.Fatal("expected all goroutines to share the cached metadata")
+		}
+	}
+}
+
+type metadataBenchmarkTarget struct {
+	A    *metadataDep        `inject:""`
+	B    benchmarkAnswerable `inject:""`
+	C    *metadataDep        `inject:"dep"`
+	Port int                 `value:"port:80"`
+	Name string              `value:"name:bench"`
+}
+
+func BenchmarkFieldMetadata(b *testing.B) {
+	typ := reflect.TypeOf(metadataBenchmarkTarget{})
+	b.Run("parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < typ.NumField(); j++ {
+				tag := string(typ.Field(j).Tag)
+				_, _ = parseTag(tag)
+				_, _, _, _, _ = parseValueTag(tag)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j := range structInfoOf(typ).fields {
+				_ = structInfoOf(typ).fields[j].tag
+			}
+		}
+	})
+}
+
+func BenchmarkPopulateSameType(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		var g Graph
+		objects := []*Object{
+			{Value: &metadataDep{}},
+			{Value: &metadataDep{}, Name: "dep"},
+			{Value: &benchmarkAnswer{}},
+		}
+		for j := 0; j < 500; j++ {
+			objects = append(objects, &Object{
+				Value: &metadataBenchmarkTarget{},
+				Name:  fmt.Sprintf("target%d", j),
+			})
+		}
+		if err := g.Provide(objects...); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		if err := g.Populate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package inject
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Module 一组可以复用的bean注册，例如共享库提供的数据库、缓存或认证组件。
+// 同一个模块被多个模块导入时只会安装一次。
+type Module struct {
+	Name         string
+	Beans        []interface{}          // 未命名的bean
+	Named        map[string]interface{} // 命名的bean
+	Constructors []interface{}          // 构造函数，形如 func(A, B, ...) (T, error)
+	Private      []interface{}          // 只能注入到本模块的bean和构造函数中的未命名bean
+	Imports      []*Module              // 依赖的模块，先于本模块安装
+}
+
+func (m *Module) String() string {
+	return fmt.Sprintf("module %s", m.Name)
+}
+
+// Install 安装模块及其导入的所有模块，已经安装过的模块会被跳过。
+// 提供bean时发生的错误会指明出错的模块，以及与之冲突的bean来自哪个模块。
+func (g *Graph) Install(modules ...*Module) error {
+	for _, m := range modules {
+		if g.modules[m] {
+			continue
+		}
+		if g.modules == nil {
+			g.modules = make(map[*Module]bool)
+		}
+		// 先标记再安装导入的模块，这样循环导入也只会安装一次。安装失败时取消标记。
+		g.modules[m] = true
+		if err := g.Install(m.Imports...); err != nil {
+			delete(g.modules, m)
+			return err
+		}
+		before := g.snapshot()
+		constructors := len(g.constructors)
+		hidden := g.hidden
+		if err := g.installModule(m); err != nil {
+			// 撤销已经提供的部分，这样修正问题之后可以再次安装。
+			g.restore(before)
+			g.constructors = g.constructors[:constructors]
+			g.hidden = hidden
+			delete(g.modules, m)
+			return m.wrap(err)
+		}
+		if g.Logger != nil {
+			g.Logger.Info("installed %v", m)
+		}
+	}
+	return nil
+}
+
+func (g *Graph) installModule(m *Module) error {
+	for _, bean := range m.Beans {
+		if err := g.Provide(&Object{Value: bean, module: m}); err != nil {
+			return err
+		}
+	}
+	names := make([]string, 0, len(m.Named))
+	for name := range m.Named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := g.Provide(&Object{Name: name, Value: m.Named[name], module: m}); err != nil {
+			return err
+		}
+	}
+	for _, bean := range m.Private {
+		if err := g.Provide(&Object{Value: bean, module: m, hidden: true}); err != nil {
+			return err
+		}
+	}
+	for _, fn := range m.Constructors {
+		if err := g.ProvideConstructor(&Constructor{Func: fn, module: m}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wrap 为安装模块m时发生的错误加上模块名称，重复提供时同时指明已有bean来自的模块。
+func (m *Module) wrap(err error) error {
+	var duplicate *DuplicateProvideError
+	if errors.As(err, &duplicate) {
+		var origin *Module
+		switch {
+		case duplicate.Existing != nil:
+			origin = duplicate.Existing.module
+		case len(duplicate.Constructors) > 0:
+			origin = duplicate.Constructors[0].module
+		}
+		if origin != nil && origin != m {
+			return fmt.Errorf("%v: %w (already provided by %v)", m, err, origin)
+		}
+	}
+	return fmt.Errorf("%v: %w", m, err)
+}
+
+// view 将正在填充的对象所属的模块设置为m，并返回恢复之前模块的函数。
+func (g *Graph) view(m *Module) func() {
+	previous := g.viewer
+	g.viewer = m
+	return func() {
+		g.viewer = previous
+	}
+}
+
+// visible 过滤掉当前模块看不到的模块私有对象。当前模块自己的私有对象优先于其他对象。
+func (g *Graph) visible(found []*Object) []*Object {
+	if g.hidden == 0 {
+		return found
+	}
+	var own, public []*Object
+	for _, o := range found {
+		switch {
+		case !o.hidden:
+			public = append(public, o)
+		case o.module == g.viewer:
+			own = append(own, o)
+		}
+	}
+	if len(own) > 0 {
+		return own
+	}
+	return public
+}
@@ -0,0 +1,188 @@
+package inject_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeForModuleConfig struct {
+	DSN string
+}
+
+type TypeForModuleDB struct {
+	Config *TypeForModuleConfig `inject:""`
+}
+
+type TypeForModuleCache struct {
+	DB *TypeForModuleDB `inject:""`
+}
+
+type TypeForModuleAuth struct {
+	DB     *TypeForModuleDB `inject:""`
+	Secret string           `inject:"secret"`
+}
+
+type TypeForModuleApp struct {
+	Cache  *TypeForModuleCache  `inject:""`
+	Auth   *TypeForModuleAuth   `inject:""`
+	Config *TypeForModuleConfig `inject:""`
+}
+
+func newTypeForModules() (db, cache, auth *inject.Module, config *TypeForModuleConfig) {
+	config = &TypeForModuleConfig{DSN: "postgres://localhost"}
+	db = &inject.Module{
+		Name:    "db",
+		Beans:   []interface{}{&TypeForModuleDB{}},
+		Private: []interface{}{config},
+	}
+	cache = &inject.Module{
+		Name:    "cache",
+		Imports: []*inject.Module{db},
+		Constructors: []interface{}{func(db *TypeForModuleDB) *TypeForModuleCache {
+			return &TypeForModuleCache{DB: db}
+		}},
+	}
+	auth = &inject.Module{
+		Name:    "auth",
+		Imports: []*inject.Module{db},
+		Beans:   []interface{}{&TypeForModuleAuth{}},
+		Named:   map[string]interface{}{"secret": "s3cr3t"},
+	}
+	return db, cache, auth, config
+}
+
+func TestModuleInstall(t *testing.T) {
+	_, cache, auth, config := newTypeForModules()
+	c := inject.NewContainer()
+	var app TypeForModuleApp
+	if err := c.Provides(&app); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Install(cache, auth); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Install(auth); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.Cache.DB != app.Auth.DB {
+		t.Fatal("expected the imported db module to be installed once")
+	}
+	if app.Auth.Secret != "s3cr3t" {
+		t.Fatal("expected the named bean of the auth module")
+	}
+	if app.Cache.DB.Config != config {
+		t.Fatal("expected the db to see the private config of its module")
+	}
+	if app.Config == config {
+		t.Fatal("expected the private config not to be visible outside of its module")
+	}
+}
+
+func TestModulePrivateNotResolvable(t *testing.T) {
+	db, _, _, _ := newTypeForModules()
+	c := inject.NewContainer()
+	if err := c.Install(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := inject.Resolve[*TypeForModuleConfig](c)
+	var missing *inject.MissingDependencyError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected the private config not to be resolvable but got %v", err)
+	}
+}
+
+func TestModulePrivateDoesNotConflict(t *testing.T) {
+	db, _, _, config := newTypeForModules()
+	c := inject.NewContainer()
+	public := &TypeForModuleConfig{DSN: "public"}
+	if err := c.Provides(public); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Install(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := inject.Resolve[*TypeForModuleDB](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Config != config {
+		t.Fatal("expected the private config to take precedence inside its module")
+	}
+}
+
+func TestModuleConflict(t *testing.T) {
+	a := &inject.Module{Name: "a", Beans: []interface{}{&TypeForModuleCache{}}}
+	b := &inject.Module{
+		Name: "b",
+		Constructors: []interface{}{func() *TypeForModuleCache {
+			return &TypeForModuleCache{}
+		}},
+	}
+	c := inject.NewContainer()
+	err := c.Install(a, b)
+	const msg = "module b: provided an unnamed instance and a constructor of type *inject_test.TypeForModuleCache (already provided by module a)"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+	var duplicate *inject.DuplicateProvideError
+	if !errors.As(err, &duplicate) {
+		t.Fatal("expected the duplicate provide error to be wrapped")
+	}
+}
+
+func TestModuleImportCycle(t *testing.T) {
+	a := &inject.Module{Name: "a", Beans: []interface{}{&TypeForModuleConfig{}}}
+	b := &inject.Module{Name: "b", Beans: []interface{}{&TypeForModuleDB{}}, Imports: []*inject.Module{a}}
+	a.Imports = []*inject.Module{b}
+	c := inject.NewContainer()
+	if err := c.Install(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestModuleRetryAfterFailure(t *testing.T) {
+	m := &inject.Module{
+		Name:         "db",
+		Beans:        []interface{}{&TypeForModuleDB{}},
+		Constructors: []interface{}{"not a constructor"},
+	}
+	c := inject.NewContainer()
+	err := c.Install(m)
+	const msg = "module db: expected constructor to be a function but got type string"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+	if err := c.Install(m); err == nil || err.Error() != msg {
+		t.Fatalf("expected a retry of the failed module to fail again but got %v", err)
+	}
+
+	m.Constructors = []interface{}{func() *TypeForModuleConfig { return &TypeForModuleConfig{} }}
+	if err := c.Install(m); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	db, err := inject.Resolve[*TypeForModuleDB](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Config == nil {
+		t.Fatal("expected the module to be installed after fixing it")
+	}
+}
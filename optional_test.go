@@ -0,0 +1,129 @@
+package inject_test
+
+import (
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeWithOptionalDependencies struct {
+	Answerable Answerable        `inject:",optional"`
+	Named      *TypeAnswerStruct `inject:"foo,optional"`
+	Pointer    *TypeAnswerStruct `inject:",optional"`
+}
+
+func TestOptionalMissing(t *testing.T) {
+	var v TypeWithOptionalDependencies
+	g := inject.Graph{
+		Logger: &logger{
+			Expected: []string{
+				"provided *inject_test.TypeWithOptionalDependencies",
+				"left optional field Named in *inject_test.TypeWithOptionalDependencies unset: did not find object named foo",
+				"left optional field Pointer in *inject_test.TypeWithOptionalDependencies unset: found no existing value",
+				"left optional field Answerable in *inject_test.TypeWithOptionalDependencies unset: found no assignable value",
+			},
+			T: t,
+		},
+	}
+	if err := g.Provide(&inject.Object{Value: &v}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.Answerable != nil || v.Named != nil || v.Pointer != nil {
+		t.Fatalf("expected optional fields to be nil but got %+v", v)
+	}
+}
+
+func TestOptionalPresent(t *testing.T) {
+	var g inject.Graph
+	var v TypeWithOptionalDependencies
+	a := &TypeAnswerStruct{}
+	foo := &TypeAnswerStruct{}
+	err := g.Provide(
+		&inject.Object{Value: a},
+		&inject.Object{Value: foo, Name: "foo"},
+		&inject.Object{Value: &v},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.Answerable != a {
+		t.Fatal("v.Answerable was not injected")
+	}
+	if v.Named != foo {
+		t.Fatal("v.Named was not injected")
+	}
+	if v.Pointer != a {
+		t.Fatal("v.Pointer was not injected")
+	}
+}
+
+func TestOptionalNamedInterface(t *testing.T) {
+	var v struct {
+		Answerable Answerable `inject:"tracer,optional"`
+	}
+	if err := inject.Populate(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Answerable != nil {
+		t.Fatal("expected the optional named interface field to be nil")
+	}
+
+	var g inject.Graph
+	tracer := &TypeAnswerStruct{}
+	err := g.Provide(
+		&inject.Object{Value: tracer, Name: "tracer"},
+		&inject.Object{Value: &v},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.Answerable != tracer {
+		t.Fatal("expected the named tracer to be injected")
+	}
+}
+
+func TestOptionalFromConstructor(t *testing.T) {
+	c := inject.NewContainer()
+	var v TypeWithOptionalDependencies
+	if err := c.Provides(&v); err != nil {
+		t.Fatal(err)
+	}
+	err := c.ProvideConstructor(func() *TypeAnswerStruct { return &TypeAnswerStruct{answer: 1} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.Pointer == nil || v.Answerable != v.Pointer {
+		t.Fatal("optional fields were not constructed")
+	}
+}
+
+type TypeWithOptionalNotAssignable struct {
+	A *TypeNestedStruct `inject:"foo,optional"`
+}
+
+func TestOptionalStillChecksAssignability(t *testing.T) {
+	var g inject.Graph
+	var v TypeWithOptionalNotAssignable
+	err := g.Provide(
+		&inject.Object{Value: &TypeAnswerStruct{}, Name: "foo"},
+		&inject.Object{Value: &v},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err == nil {
+		t.Fatal("expected error")
+	}
+}
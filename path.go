@@ -0,0 +1,56 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// pathEntry 依赖路径中的一步：某个对象的某个字段（或构造函数的参数）。
+type pathEntry struct {
+	owner reflect.Type
+	name  string // owner为命名对象时的名称
+	field string
+	deep  bool // 如果为true，这一步是对已有值的深度注入
+}
+
+func (e pathEntry) String() string {
+	if e.name != "" {
+		return fmt.Sprintf("(%s named %s).%s", e.owner, e.name, e.field)
+	}
+	return fmt.Sprintf("%s.%s", e.owner, e.field)
+}
+
+// enter 记录正在为某个字段创建依赖，用于在错误信息中给出依赖路径。
+// 如果同一个字段已经在创建过程中，说明出现了无法终止的循环依赖。
+func (g *Graph) enter(owner reflect.Type, name, field string) error {
+	for i, e := range g.path {
+		if !e.deep && e.owner == owner && e.name == name && e.field == field {
+			cycle := make([]string, 0, len(g.path)-i)
+			for _, e := range g.path[i:] {
+				cycle = append(cycle, e.String())
+			}
+			return &CycleError{Path: cycle}
+		}
+	}
+	g.path = append(g.path, pathEntry{owner: owner, name: name, field: field})
+	return nil
+}
+
+// follow 记录正在对某个字段中的已有值进行深度注入。已有值在注入前已经加入依赖图，
+// 因此同一个字段可以合法地重复出现，例如手动创建的链表。
+func (g *Graph) follow(owner reflect.Type, name, field string) {
+	g.path = append(g.path, pathEntry{owner: owner, name: name, field: field, deep: true})
+}
+
+func (g *Graph) leave() {
+	g.path = g.path[:len(g.path)-1]
+}
+
+func (g *Graph) pathString() string {
+	entries := make([]string, 0, len(g.path))
+	for _, e := range g.path {
+		entries = append(entries, e.String())
+	}
+	return strings.Join(entries, " -> ")
+}
@@ -0,0 +1,202 @@
+package inject
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Plan Graph.Plan计算出的注入计划，通过Graph.Apply提交。
+type Plan struct {
+	Assignments []Assignment // 所有将要进行的字段赋值，按注入顺序排列
+	Created     []*Object    // 将由我们创建的对象，按创建顺序排列
+	graph       *Graph
+	seq         int // 计划开始时依赖图中的对象数量
+	deps        []planDep
+	state       graphState
+	applied     bool
+	visited     map[*Object]bool // 已经进行过第二遍注入的对象
+	inits       []*Object        // 等到Apply时才调用Init的原型实例
+}
+
+// Assignment 计划中的一次字段赋值
+type Assignment struct {
+	Object *Object     // 被注入的对象
+	Field  string      // 字段名称，value标签的嵌套字段以"."连接
+	Value  interface{} // 将要赋给字段的值
+	target reflect.Value
+	value  reflect.Value
+}
+
+func (a Assignment) String() string {
+	return fmt.Sprintf("%s in %v", a.Field, a.Object)
+}
+
+// planDep 计划中对已有对象记录的依赖
+type planDep struct {
+	object *Object
+	field  string
+	dep    *Object
+}
+
+// graphState 填充过程中会改变的依赖图状态
+type graphState struct {
+	unnamed     []*Object
+	unnamedType map[reflect.Type]bool
+	named       map[string]*Object
+	seq         int
+	built       map[*Constructor]*Object
+	decorated   map[decoration]*Object
+}
+
+func (g *Graph) snapshot() graphState {
+	s := graphState{
+		unnamed:     append([]*Object(nil), g.unnamed...),
+		unnamedType: make(map[reflect.Type]bool, len(g.unnamedType)),
+		named:       make(map[string]*Object, len(g.named)),
+		seq:         g.seq,
+		built:       make(map[*Constructor]*Object, len(g.constructors)),
+		decorated:   make(map[decoration]*Object, len(g.decorated)),
+	}
+	for t, v := range g.unnamedType {
+		s.unnamedType[t] = v
+	}
+	for name, o := range g.named {
+		s.named[name] = o
+	}
+	for _, c := range g.constructors {
+		s.built[c] = c.built
+	}
+	for key, o := range g.decorated {
+		s.decorated[key] = o
+	}
+	return s
+}
+
+func (g *Graph) restore(s graphState) {
+	g.unnamed = s.unnamed
+	g.reindex()
+	g.unnamedType = s.unnamedType
+	g.named = s.named
+	g.seq = s.seq
+	g.decorated = s.decorated
+	for _, c := range g.constructors {
+		c.built = s.built[c]
+	}
+}
+
+// Plan 计算Populate将要进行的所有注入，但不修改任何已提供的对象以及依赖图本身，
+// 可以在接触正在使用的实例之前检查依赖配置。
+// 需要创建的对象会被创建并填充，需要的构造函数也会被调用，但它们在Apply之前不会加入依赖图；
+// 计划失败时这些结果被丢弃，构造函数的副作用无法撤销。原型实例的Init推迟到Apply时调用。
+func (g *Graph) Plan() (*Plan, error) {
+	if g.plan != nil {
+		return nil, errors.New("plan is already in progress")
+	}
+	before := g.snapshot()
+	p := &Plan{graph: g, seq: g.seq}
+	g.plan = p
+	err := g.Populate()
+	g.plan = nil
+	p.state = g.snapshot()
+	g.restore(before)
+	g.path = nil
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range p.state.unnamed {
+		if o.created && o.seq > p.seq {
+			p.Created = append(p.Created, o)
+		}
+	}
+	for _, o := range p.state.named {
+		if o.created && o.seq > p.seq {
+			p.Created = append(p.Created, o)
+		}
+	}
+	sort.Slice(p.Created, func(i, j int) bool {
+		return p.Created[i].seq < p.Created[j].seq
+	})
+	return p, nil
+}
+
+// Apply 提交Plan计算出的注入计划。如果计划之后依赖图中提供了新的对象，
+// 或者计划中的任何字段已经被修改，Apply不会修改任何字段并返回错误。
+func (g *Graph) Apply(p *Plan) error {
+	if p.graph != g {
+		return errors.New("plan was computed for a different graph")
+	}
+	if p.applied {
+		return errors.New("plan was already applied")
+	}
+	if g.seq != p.seq {
+		return errors.New("plan is stale: objects were provided after planning")
+	}
+	for _, a := range p.Assignments {
+		if !p.fresh(a.Object) && !isNilOrZero(a.target, a.target.Type()) {
+			return fmt.Errorf("plan is stale: field %s in %v was modified after planning", a.Field, a.Object)
+		}
+	}
+
+	for _, a := range p.Assignments {
+		if !p.fresh(a.Object) {
+			a.target.Set(a.value)
+		}
+	}
+	for _, d := range p.deps {
+		d.object.addDep(d.field, d.dep)
+	}
+	g.restore(p.state)
+	p.applied = true
+
+	if g.Logger != nil {
+		g.Logger.Info("applied plan with %d assignments", len(p.Assignments))
+	}
+	return g.runScopedInits(p.inits)
+}
+
+// visit 返回o的第二遍注入是否尚未计划过，并将其标记为已计划。
+func (p *Plan) visit(o *Object) bool {
+	if p.visited[o] {
+		return false
+	}
+	if p.visited == nil {
+		p.visited = make(map[*Object]bool)
+	}
+	p.visited[o] = true
+	return true
+}
+
+// fresh 返回o是否由计划创建，这样的对象在计划之外不可见，可以直接修改。
+func (p *Plan) fresh(o *Object) bool {
+	return o.created && o.seq > p.seq
+}
+
+// set 将value赋给o中名为field的字段target。计划过程中对已有对象的赋值被推迟到Apply。
+func (g *Graph) set(o *Object, field string, target, value reflect.Value) {
+	if g.plan == nil {
+		target.Set(value)
+		return
+	}
+	g.plan.Assignments = append(g.plan.Assignments, Assignment{
+		Object: o,
+		Field:  field,
+		Value:  value.Interface(),
+		target: target,
+		value:  value,
+	})
+	if g.plan.fresh(o) {
+		target.Set(value)
+	}
+}
+
+// depend 记录o的字段field依赖dep。计划过程中对已有对象的记录被推迟到Apply。
+func (g *Graph) depend(o *Object, field string, dep *Object) {
+	if g.plan != nil && o.seq <= g.plan.seq {
+		g.plan.deps = append(g.plan.deps, planDep{object: o, field: field, dep: dep})
+		return
+	}
+	o.addDep(field, dep)
+}
@@ -0,0 +1,274 @@
+package inject_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+func TestPlanDoesNotMutate(t *testing.T) {
+	var g inject.Graph
+	var v TypeInjectInterface
+	a := &TypeAnswerStruct{}
+	if err := g.Provide(&inject.Object{Value: &v}, &inject.Object{Value: a}); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := g.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.A != nil || v.Answerable != nil {
+		t.Fatal("plan modified the provided object")
+	}
+	if len(plan.Assignments) != 2 || len(plan.Created) != 0 {
+		t.Fatalf("unexpected plan %v with created %v", plan.Assignments, plan.Created)
+	}
+	if plan.Assignments[0].Field != "A" || plan.Assignments[0].Value != a {
+		t.Fatalf("unexpected assignment %v", plan.Assignments[0])
+	}
+
+	if err := g.Apply(plan); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != a || v.Answerable != a {
+		t.Fatal("plan was not applied")
+	}
+}
+
+type TypeForPlanCreated struct {
+	N *TypeNestedStruct `inject:""`
+}
+
+func TestPlanCreatedObjects(t *testing.T) {
+	var g inject.Graph
+	var v TypeForPlanCreated
+	if err := g.Provide(&inject.Object{Value: &v}); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := g.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.N != nil {
+		t.Fatal("plan modified the provided object")
+	}
+	if len(g.Objects()) != 1 {
+		t.Fatalf("plan added objects to the graph: %v", g.Objects())
+	}
+	if len(plan.Created) != 2 {
+		t.Fatalf("expected two created objects but got %v", plan.Created)
+	}
+	last := plan.Assignments[len(plan.Assignments)-1]
+	if last.Field != "N" || last.Value != plan.Created[0].Value {
+		t.Fatalf("unexpected assignments %v for created objects %v", plan.Assignments, plan.Created)
+	}
+
+	if err := g.Apply(plan); err != nil {
+		t.Fatal(err)
+	}
+	if v.N == nil || v.N.A == nil {
+		t.Fatal("plan was not applied")
+	}
+	if len(g.Objects()) != 3 {
+		t.Fatalf("expected created objects in the graph but got %v", g.Objects())
+	}
+}
+
+type TypeForPlanFailure struct {
+	A *TypeAnswerStruct `inject:""`
+	B *TypeAnswerStruct `inject:"foo"`
+}
+
+func TestPlanFailureLeavesObjectsUntouched(t *testing.T) {
+	var g inject.Graph
+	var v TypeForPlanFailure
+	if err := g.Provide(&inject.Object{Value: &v}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Plan(); err == nil {
+		t.Fatal("expected error")
+	}
+	if v.A != nil {
+		t.Fatal("failed plan modified the provided object")
+	}
+	if len(g.Objects()) != 1 {
+		t.Fatalf("failed plan added objects to the graph: %v", g.Objects())
+	}
+}
+
+func TestPlanWithConstructorAndValue(t *testing.T) {
+	c := inject.NewContainer()
+	c.AddPropertySource(inject.MapSource{"server.port": "8080"})
+	var called int
+	err := c.ProvideConstructor(func() *TypeForConstructorDB {
+		called++
+		return &TypeForConstructorDB{DSN: "postgres://localhost"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		DB   *TypeForConstructorDB `inject:""`
+		Port int                   `value:"server.port"`
+	}
+	if err := c.Provides(&v); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := c.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.DB != nil || v.Port != 0 {
+		t.Fatal("plan modified the provided object")
+	}
+	if err := c.Apply(plan); err != nil {
+		t.Fatal(err)
+	}
+	if v.DB == nil || v.Port != 8080 {
+		t.Fatalf("plan was not applied: %+v", v)
+	}
+
+	db, err := inject.Resolve[*TypeForConstructorDB](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db != v.DB || called != 1 {
+		t.Fatalf("expected the planned instance but constructor was called %d times", called)
+	}
+}
+
+func TestApplyStalePlan(t *testing.T) {
+	var g inject.Graph
+	var v TypeInjectInterface
+	if err := g.Provide(&inject.Object{Value: &v}, &inject.Object{Value: &TypeAnswerStruct{}}); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := g.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manual := &TypeAnswerStruct{}
+	v.A = manual
+	err = g.Apply(plan)
+	const msg = "plan is stale: field A in *inject_test.TypeInjectInterface was modified after planning"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+	if v.A != manual || v.Answerable != nil {
+		t.Fatal("stale plan was partially applied")
+	}
+
+	v.A = nil
+	if err := g.Provide(&inject.Object{Value: &TypeNestedStruct{}}); err != nil {
+		t.Fatal(err)
+	}
+	err = g.Apply(plan)
+	if err == nil || err.Error() != "plan is stale: objects were provided after planning" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestApplyTwice(t *testing.T) {
+	var g inject.Graph
+	if err := g.Provide(&inject.Object{Value: &TypeNestedStruct{}}); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := g.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Apply(plan); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Apply(plan); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestPlanNamedInterface(t *testing.T) {
+	c := inject.NewContainer()
+	db := &TypeAnswerStruct{}
+	var v struct {
+		Store Answerable `inject:"db"`
+	}
+	if err := c.Provides(&v); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideWithName("db", db); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := c.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Store != nil || len(plan.Assignments) != 1 {
+		t.Fatalf("unexpected plan %v", plan.Assignments)
+	}
+	if err := c.Apply(plan); err != nil {
+		t.Fatal(err)
+	}
+	if v.Store != db {
+		t.Fatal("plan was not applied")
+	}
+}
+
+type TypeForPlanSession struct {
+	Inits *int
+}
+
+func (s *TypeForPlanSession) Init(ctx context.Context) error {
+	*s.Inits++
+	return nil
+}
+
+type TypeForPlanHolder struct {
+	Sessions []*TypeForPlanSession `inject:""`
+}
+
+func (*TypeForPlanHolder) Answer() int { return 42 }
+
+func TestPlanVisitsExistingObjectsOnce(t *testing.T) {
+	c := inject.NewContainer()
+	var v struct {
+		Answerable Answerable `inject:""`
+	}
+	if err := c.Provides(&v, &TypeForPlanHolder{}); err != nil {
+		t.Fatal(err)
+	}
+	var constructed, inits int
+	err := c.ProvidePrototypeConstructor(func() *TypeForPlanSession {
+		constructed++
+		return &TypeForPlanSession{Inits: &inits}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = inject.Decorate[Answerable](c, func(a Answerable) Answerable { return a })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := c.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if constructed != 1 || len(plan.Assignments) != 2 {
+		t.Fatalf("expected the holder to be planned once but the constructor was called %d times for %v",
+			constructed, plan.Assignments)
+	}
+	if inits != 0 {
+		t.Fatal("expected Init to wait for Apply")
+	}
+	if err := c.Apply(plan); err != nil {
+		t.Fatal(err)
+	}
+	if inits != 1 {
+		t.Fatalf("expected Init to run once on Apply but it ran %d times", inits)
+	}
+}
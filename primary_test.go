@@ -0,0 +1,101 @@
+package inject_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeForPrimaryNotifier interface {
+	Notify() string
+}
+
+type TypeForPrimaryEmail struct{}
+
+func (*TypeForPrimaryEmail) Notify() string { return "email" }
+
+type TypeForPrimarySMS struct{}
+
+func (*TypeForPrimarySMS) Notify() string { return "sms" }
+
+type TypeForPrimaryUser struct {
+	Notifier  TypeForPrimaryNotifier   `inject:""`
+	Notifiers []TypeForPrimaryNotifier `inject:""`
+}
+
+func TestPrimary(t *testing.T) {
+	c := inject.NewContainer()
+	var user TypeForPrimaryUser
+	sms := &TypeForPrimarySMS{}
+	if err := c.Provides(&user, &TypeForPrimaryEmail{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvidePrimary(sms); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Notifier != sms {
+		t.Fatal("expected the primary notifier")
+	}
+	if len(user.Notifiers) != 2 {
+		t.Fatal("expected slices to still collect every notifier")
+	}
+	resolved, err := inject.Resolve[TypeForPrimaryNotifier](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != sms {
+		t.Fatal("expected Resolve to prefer the primary notifier")
+	}
+}
+
+func TestPrimaryConflict(t *testing.T) {
+	var g inject.Graph
+	var user struct {
+		Notifier TypeForPrimaryNotifier `inject:""`
+	}
+	err := g.Provide(
+		&inject.Object{Value: &user},
+		&inject.Object{Value: &TypeForPrimaryEmail{}, Primary: true},
+		&inject.Object{Value: &TypeForPrimarySMS{}, Primary: true},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = g.Populate()
+	const msg = "found two primary values for field Notifier in type *struct { Notifier inject_test.TypeForPrimaryNotifier \"inject:\\\"\\\"\" }. " +
+		"one type *inject_test.TypeForPrimaryEmail with value &{} and another type *inject_test.TypeForPrimarySMS with value &{}"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+	var ambiguous *inject.AmbiguousDependencyError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+		t.Fatal("expected both primaries as candidates")
+	}
+}
+
+func TestPrimaryAmongQualified(t *testing.T) {
+	var g inject.Graph
+	var user struct {
+		Prod TypeForPrimaryNotifier `inject:"qualifier=env=prod"`
+	}
+	sms := &TypeForPrimarySMS{}
+	err := g.Provide(
+		&inject.Object{Value: &user},
+		&inject.Object{Value: &TypeForPrimaryEmail{}, Qualifiers: map[string]string{"env": "prod"}},
+		&inject.Object{Value: sms, Qualifiers: map[string]string{"env": "prod"}, Primary: true},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if user.Prod != sms {
+		t.Fatal("expected the primary among the qualified notifiers")
+	}
+}
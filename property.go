@@ -0,0 +1,260 @@
+package inject
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PropertySource 配置来源，按键返回字符串形式的配置值
+type PropertySource interface {
+	Property(key string) (string, bool)
+}
+
+// MapSource 基于map的配置来源
+type MapSource map[string]string
+
+func (m MapSource) Property(key string) (string, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+// EnvSource 基于环境变量的配置来源。
+// 键中的"."和"-"会被替换为"_"并转换为大写，例如server.port对应SERVER_PORT。
+type EnvSource struct {
+	Prefix string // 可选的，环境变量名称的前缀，例如"APP_"
+}
+
+var envReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+func (s EnvSource) Property(key string) (string, bool) {
+	return os.LookupEnv(s.Prefix + strings.ToUpper(envReplacer.Replace(key)))
+}
+
+// NewJSONFileSource 读取JSON文件作为配置来源。嵌套的对象被展开为以"."连接的键，
+// 标量数组被展开为以","连接的值，例如 {"server": {"port": 8080}} 对应键server.port。
+func NewJSONFileSource(path string) (MapSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var root map[string]interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("failed to parse property file %s: %w", path, err)
+	}
+
+	source := make(MapSource)
+	flattenJSON(source, "", root)
+	return source, nil
+}
+
+func flattenJSON(source MapSource, key string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if key != "" {
+				k = key + "." + k
+			}
+			flattenJSON(source, k, child)
+		}
+	case []interface{}:
+		scalars := make([]string, 0, len(v))
+		for i, child := range v {
+			flattenJSON(source, fmt.Sprintf("%s.%d", key, i), child)
+			switch child.(type) {
+			case map[string]interface{}, []interface{}, nil:
+			default:
+				scalars = append(scalars, fmt.Sprint(child))
+			}
+		}
+		if len(scalars) == len(v) {
+			source[key] = strings.Join(scalars, ",")
+		}
+	case nil:
+	default:
+		source[key] = fmt.Sprint(v)
+	}
+}
+
+// property 按顺序在所有配置来源中查找键，先提供的来源优先，最后查找父级的配置来源。
+func (g *Graph) property(key string) (string, bool) {
+	for _, source := range g.Properties {
+		if v, ok := source.Property(key); ok {
+			return v, true
+		}
+	}
+	if g.parent != nil {
+		return g.parent.property(key)
+	}
+	return "", false
+}
+
+// parseValueTag 解析value标签，格式为"key"或"key:default"。
+func parseValueTag(t string) (found bool, key string, def string, hasDefault bool, err error) {
+	found, value, err := Extract("value", t)
+	if err != nil || !found {
+		return found, "", "", false, err
+	}
+	key, def, hasDefault = strings.Cut(value, ":")
+	if key == "" {
+		return false, "", "", false, ErrInvalidTag
+	}
+	return true, key, def, hasDefault, nil
+}
+
+// populateValue 使用配置来源填充o中路径为fieldPath、带有value标签的字段。
+func (g *Graph) populateValue(o *Object, fieldPath string, field reflect.Value, key, def string, hasDefault bool) error {
+	if !field.CanSet() {
+		return &InvalidFieldError{
+			Owner:  o.reflectType,
+			Field:  fieldPath,
+			format: "value requested on unexported field %s in type %s",
+		}
+	}
+
+	// 嵌套结构体按前缀逐个填充其中带有value标签的字段。
+	if field.Kind() == reflect.Struct && !isTextUnmarshaler(field) {
+		return g.populateValueStruct(o, fieldPath, field, key)
+	}
+
+	// 不要覆盖现有值。
+	if !isNilOrZero(field, field.Type()) {
+		return nil
+	}
+
+	value, ok := g.property(key)
+	if !ok {
+		if !hasDefault {
+			return &MissingDependencyError{
+				Owner:    o.reflectType,
+				Field:    fieldPath,
+				Type:     field.Type(),
+				Property: key,
+			}
+		}
+		value = def
+	}
+
+	v := reflect.New(field.Type()).Elem()
+	if err := setValue(v, value); err != nil {
+		return fmt.Errorf(
+			"cannot convert property %s=%q for field %s in type %s: %w",
+			key,
+			value,
+			fieldPath,
+			o.reflectType,
+			err,
+		)
+	}
+	g.set(o, fieldPath, field, v)
+	if g.Logger != nil {
+		g.Logger.Info("assigned property %s to field %s in %v", key, fieldPath, o)
+	}
+	return nil
+}
+
+// populateValueStruct 以prefix为前缀填充嵌套结构体v中带有value标签的字段。
+func (g *Graph) populateValueStruct(o *Object, path string, v reflect.Value, prefix string) error {
+	for i, info := range structInfoOf(v.Type()).fields {
+		fieldPath := path + "." + info.name
+		if info.valueErr != nil {
+			return &InvalidTagError{
+				Owner: o.reflectType,
+				Field: fieldPath,
+				Tag:   info.rawTag,
+				Err:   info.valueErr,
+			}
+		}
+		if !info.value {
+			continue
+		}
+
+		if err := g.populateValue(o, fieldPath, v.Field(i), prefix+"."+info.valueKey, info.valueDef, info.hasDefault); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func isTextUnmarshaler(v reflect.Value) bool {
+	return reflect.PointerTo(v.Type()).Implements(textUnmarshalerType)
+}
+
+// setValue 将字符串转换为v的类型并赋值。切片使用","分隔元素。
+func setValue(v reflect.Value, s string) error {
+	if v.CanAddr() && isTextUnmarshaler(v) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		var parts []string
+		if s != "" {
+			parts = strings.Split(s, ",")
+		}
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setValue(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
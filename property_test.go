@@ -0,0 +1,258 @@
+package inject_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeForValueDatabase struct {
+	Host    string        `value:"host:localhost"`
+	Port    int           `value:"port"`
+	Timeout time.Duration `value:"timeout:5s"`
+}
+
+type TypeForValueServer struct {
+	Port     uint16               `value:"server.port"`
+	Debug    bool                 `value:"server.debug:false"`
+	Ratio    float64              `value:"server.ratio:0.5"`
+	Tags     []string             `value:"server.tags"`
+	Ports    []int                `value:"server.ports:"`
+	Limit    *int                 `value:"server.limit:10"`
+	Listen   net.IP               `value:"server.listen:127.0.0.1"`
+	Database TypeForValueDatabase `value:"db"`
+	Answer   *TypeAnswerStruct    `inject:""`
+}
+
+func TestValueInjection(t *testing.T) {
+	c := inject.NewContainer()
+	c.AddPropertySource(inject.MapSource{
+		"server.port":  "8080",
+		"server.debug": "true",
+		"server.tags":  "a, b,c",
+		"db.port":      "5432",
+		"db.timeout":   "1m",
+	})
+	var v TypeForValueServer
+	if err := c.Provides(&v); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Port != 8080 {
+		t.Fatalf("unexpected port %d", v.Port)
+	}
+	if !v.Debug {
+		t.Fatal("expected debug to be true")
+	}
+	if v.Ratio != 0.5 {
+		t.Fatalf("unexpected ratio %f", v.Ratio)
+	}
+	if !reflect.DeepEqual(v.Tags, []string{"a", "b", "c"}) {
+		t.Fatalf("unexpected tags %v", v.Tags)
+	}
+	if v.Ports == nil || len(v.Ports) != 0 {
+		t.Fatalf("expected empty ports but got %v", v.Ports)
+	}
+	if v.Limit == nil || *v.Limit != 10 {
+		t.Fatalf("unexpected limit %v", v.Limit)
+	}
+	if !v.Listen.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Fatalf("unexpected listen address %v", v.Listen)
+	}
+	expected := TypeForValueDatabase{Host: "localhost", Port: 5432, Timeout: time.Minute}
+	if v.Database != expected {
+		t.Fatalf("expected %+v but got %+v", expected, v.Database)
+	}
+	if v.Answer == nil {
+		t.Fatal("v.Answer is nil")
+	}
+}
+
+func TestValueDoesNotOverwrite(t *testing.T) {
+	c := inject.NewContainer()
+	c.AddPropertySource(inject.MapSource{"db.host": "db.internal", "db.port": "5432"})
+	v := struct {
+		Database TypeForValueDatabase `value:"db"`
+	}{
+		Database: TypeForValueDatabase{Host: "manual"},
+	}
+	if err := c.Provides(&v); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.Database.Host != "manual" {
+		t.Fatalf("existing value was overwritten with %s", v.Database.Host)
+	}
+	if v.Database.Port != 5432 {
+		t.Fatalf("unexpected port %d", v.Database.Port)
+	}
+}
+
+func TestValueSourcePrecedence(t *testing.T) {
+	c := inject.NewContainer()
+	c.AddPropertySource(inject.MapSource{"name": "first"})
+	c.AddPropertySource(inject.MapSource{"name": "second", "other": "second"})
+	var v struct {
+		Name  string `value:"name"`
+		Other string `value:"other"`
+	}
+	if err := c.Provides(&v); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "first" || v.Other != "second" {
+		t.Fatalf("unexpected values %+v", v)
+	}
+}
+
+func TestEnvSource(t *testing.T) {
+	t.Setenv("APP_SERVER_PORT", "9090")
+	t.Setenv("APP_FEATURE_FLAG_ENABLED", "true")
+
+	source := inject.EnvSource{Prefix: "APP_"}
+	if v, ok := source.Property("server.port"); !ok || v != "9090" {
+		t.Fatalf("unexpected server.port %q", v)
+	}
+	if v, ok := source.Property("feature-flag.enabled"); !ok || v != "true" {
+		t.Fatalf("unexpected feature-flag.enabled %q", v)
+	}
+	if _, ok := source.Property("missing"); ok {
+		t.Fatal("found missing property")
+	}
+}
+
+func TestJSONFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	const config = `{
+		"server": {"port": 8080, "debug": true, "tags": ["a", "b"]},
+		"db": {"hosts": [{"name": "primary"}], "dsn": null}
+	}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := inject.NewJSONFileSource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := inject.MapSource{
+		"server.port":     "8080",
+		"server.debug":    "true",
+		"server.tags":     "a,b",
+		"server.tags.0":   "a",
+		"server.tags.1":   "b",
+		"db.hosts.0.name": "primary",
+	}
+	if !reflect.DeepEqual(source, expected) {
+		t.Fatalf("expected %v but got %v", expected, source)
+	}
+}
+
+func TestJSONFileSourceInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := inject.NewJSONFileSource(path)
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to parse property file") {
+		t.Fatalf("expected parse error but got %v", err)
+	}
+}
+
+type TypeWithMissingProperty struct {
+	Port int `value:"server.port"`
+}
+
+func TestValueMissing(t *testing.T) {
+	var v TypeWithMissingProperty
+	err := inject.Populate(&v)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "did not find property server.port required by field Port in type *inject_test.TypeWithMissingProperty"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+type TypeWithNestedMissingProperty struct {
+	Database TypeForValueDatabase `value:"db"`
+}
+
+func TestValueNestedMissing(t *testing.T) {
+	var v TypeWithNestedMissingProperty
+	err := inject.Populate(&v)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "did not find property db.port required by field Database.Port in type *inject_test.TypeWithNestedMissingProperty"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+type TypeWithInvalidProperty struct {
+	Port int `value:"port:eighty"`
+}
+
+func TestValueInvalid(t *testing.T) {
+	var v TypeWithInvalidProperty
+	err := inject.Populate(&v)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = `cannot convert property port="eighty" for field Port in type *inject_test.TypeWithInvalidProperty: strconv.ParseInt: parsing "eighty": invalid syntax`
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+type TypeWithInjectAndValue struct {
+	A *TypeAnswerStruct `inject:"" value:"a"`
+}
+
+func TestValueWithInject(t *testing.T) {
+	var v TypeWithInjectAndValue
+	err := inject.Populate(&v)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "found both inject and value tags on field A in type *inject_test.TypeWithInjectAndValue"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+type TypeWithValueOnUnexportedField struct {
+	port int `value:"port:80"`
+}
+
+func TestValueOnUnexportedField(t *testing.T) {
+	var v TypeWithValueOnUnexportedField
+	err := inject.Populate(&v)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "value requested on unexported field port in type *inject_test.TypeWithValueOnUnexportedField"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s %d", msg, err.Error(), v.port)
+	}
+}
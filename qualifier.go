@@ -0,0 +1,134 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// qualifierPrefix 标签中限定符选项的前缀，例如 `inject:"qualifier=region=eu"`。
+const qualifierPrefix = "qualifier="
+
+// parseQualifier 解析形如key=value的限定符。
+func parseQualifier(s string) (key, value string, ok bool) {
+	key, value, ok = strings.Cut(s, "=")
+	return key, value, ok && key != ""
+}
+
+// parseQualifiers 将形如key=value的限定符列表解析为map。
+func parseQualifiers(qualifiers []string) (map[string]string, error) {
+	parsed := make(map[string]string, len(qualifiers))
+	for _, q := range qualifiers {
+		key, value, ok := parseQualifier(q)
+		if !ok {
+			return nil, fmt.Errorf("invalid qualifier %q: expected key=value", q)
+		}
+		parsed[key] = value
+	}
+	return parsed, nil
+}
+
+// formatQualifiers 以按键排序的key=value形式输出限定符。
+func formatQualifiers(qualifiers map[string]string) string {
+	pairs := make([]string, 0, len(qualifiers))
+	for key, value := range qualifiers {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// matches 返回o是否带有所有要求的限定符。
+func (o *Object) matches(qualifiers map[string]string) bool {
+	for key, value := range qualifiers {
+		if v, ok := o.Qualifiers[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// pick 为不带限定符的依赖从可分配给t的对象中选择一个，所有按类型的查找都使用同样的规则：
+// 主要对象优先，其次是不带限定符的对象。结构体指针有多个不带限定符的实例时（例如深度注入）选择第一个。
+// 无法选择时返回nil和所有候选对象，由调用方报告歧义；没有可分配的对象时都返回nil。
+func pick(t reflect.Type, found []*Object) (*Object, []*Object) {
+	found = preferPrimary(found)
+	if len(found) > 1 {
+		var plain []*Object
+		for _, o := range found {
+			if len(o.Qualifiers) == 0 {
+				plain = append(plain, o)
+			}
+		}
+		if len(plain) == 0 || (len(plain) > 1 && t.Kind() == reflect.Interface) {
+			if len(plain) > 1 {
+				found = plain
+			}
+			return nil, found
+		}
+		found = plain
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	return found[0], nil
+}
+
+// qualified 以提供顺序返回所有可分配给t且带有所有要求的限定符的非私有未命名对象，有多个时只返回主要对象。
+func (g *Graph) qualified(t reflect.Type, qualifiers map[string]string) []*Object {
+	var found []*Object
+	for _, o := range g.assignable(t) {
+		if o.matches(qualifiers) {
+			found = append(found, o)
+		}
+	}
+	return preferPrimary(found)
+}
+
+// populateQualified 将唯一一个带有标签中所有限定符的对象注入到o的第i个字段中。
+func (g *Graph) populateQualified(o *Object, i int, tag *tag) error {
+	info := &o.structInfo().fields[i]
+	field := o.reflectValue.Elem().Field(i)
+
+	found := g.qualified(info.typ, tag.Qualifiers)
+	switch len(found) {
+	case 0:
+		if tag.Optional {
+			if g.Logger != nil {
+				g.Logger.Info(
+					"left optional field %s in %v unset: found no value with qualifiers %s",
+					info.name,
+					o,
+					formatQualifiers(tag.Qualifiers),
+				)
+			}
+			return nil
+		}
+		return &MissingDependencyError{
+			Owner:      o.reflectType,
+			Field:      info.name,
+			Type:       info.typ,
+			Qualifiers: tag.Qualifiers,
+		}
+	case 1:
+	default:
+		return &AmbiguousDependencyError{
+			Owner:      o.reflectType,
+			Field:      info.name,
+			Type:       info.typ,
+			Candidates: found,
+		}
+	}
+
+	existing, err := g.decorate(info.typ, found[0])
+	if err != nil {
+		return err
+	}
+	g.set(o, info.name, field, reflect.ValueOf(existing.Value))
+	if g.Logger != nil {
+		g.Logger.Info("assigned qualified %v to field %s in %v", existing, info.name, o)
+	}
+	g.depend(o, info.name, existing)
+	return nil
+}
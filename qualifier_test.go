@@ -0,0 +1,258 @@
+package inject_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeForQualifierStore interface {
+	Region() string
+}
+
+type TypeForQualifierS3 struct {
+	region string
+}
+
+func (s *TypeForQualifierS3) Region() string { return s.region }
+
+type TypeForQualifierUser struct {
+	EU      TypeForQualifierStore   `inject:"qualifier=region=eu"`
+	USProd  *TypeForQualifierS3     `inject:"qualifier=region=us,qualifier=env=prod"`
+	Prod    []TypeForQualifierStore `inject:",qualifier=env=prod"`
+	Missing *TypeForQualifierS3     `inject:"qualifier=region=ap,optional"`
+}
+
+func newTypeForQualifierContainer(t *testing.T) (*inject.Container, *TypeForQualifierS3, *TypeForQualifierS3, *TypeForQualifierS3) {
+	c := inject.NewContainer()
+	eu := &TypeForQualifierS3{region: "eu"}
+	us := &TypeForQualifierS3{region: "us"}
+	usDev := &TypeForQualifierS3{region: "us"}
+	if err := c.ProvideQualified(eu, "region=eu", "env=prod"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideQualified(us, "region=us", "env=prod"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideQualified(usDev, "region=us", "env=dev"); err != nil {
+		t.Fatal(err)
+	}
+	return c, eu, us, usDev
+}
+
+func TestQualifiers(t *testing.T) {
+	c, eu, us, _ := newTypeForQualifierContainer(t)
+	var user TypeForQualifierUser
+	if err := c.Provides(&user); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if user.EU != eu {
+		t.Fatal("expected the eu store")
+	}
+	if user.USProd != us {
+		t.Fatal("expected the us prod store")
+	}
+	if len(user.Prod) != 2 || user.Prod[0] != eu || user.Prod[1] != us {
+		t.Fatalf("expected the prod stores in provide order but got %v", user.Prod)
+	}
+	if user.Missing != nil {
+		t.Fatal("expected the optional qualified field to stay nil")
+	}
+
+	resolved, err := inject.ResolveQualified[TypeForQualifierStore](c, "region=us", "env=dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Region() != "us" || resolved == TypeForQualifierStore(us) {
+		t.Fatal("expected the us dev store")
+	}
+}
+
+func TestQualifierAmbiguous(t *testing.T) {
+	c, _, _, _ := newTypeForQualifierContainer(t)
+	var user struct {
+		US TypeForQualifierStore `inject:"qualifier=region=us"`
+	}
+	if err := c.Provides(&user); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Populate()
+	var ambiguous *inject.AmbiguousDependencyError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+		t.Fatalf("expected two ambiguous candidates but got %v", err)
+	}
+}
+
+func TestUnqualifiedPointerAmbiguous(t *testing.T) {
+	c, _, _, _ := newTypeForQualifierContainer(t)
+	var user struct {
+		S3 *TypeForQualifierS3 `inject:""`
+	}
+	if err := c.Provides(&user); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Populate()
+	var ambiguous *inject.AmbiguousDependencyError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 3 || ambiguous.Field != "S3" {
+		t.Fatalf("expected an unqualified pointer field to be ambiguous but got %v", err)
+	}
+	if user.S3 != nil {
+		t.Fatal("expected the field to be left unset")
+	}
+
+	c, _, _, _ = newTypeForQualifierContainer(t)
+	plain := &TypeForQualifierS3{region: "local"}
+	if err := c.Provides(&user, plain); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if user.S3 != plain {
+		t.Fatal("expected an unqualified pointer field to prefer the unqualified value")
+	}
+}
+
+func TestQualifierMissing(t *testing.T) {
+	c, _, _, _ := newTypeForQualifierContainer(t)
+	var user struct {
+		AP TypeForQualifierStore `inject:"qualifier=region=ap,qualifier=env=prod"`
+	}
+	if err := c.Provides(&user); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Populate()
+	const msg = "found no assignable value for type inject_test.TypeForQualifierStore with qualifiers env=prod,region=ap " +
+		"required by field AP in type *struct { AP inject_test.TypeForQualifierStore \"inject:\\\"qualifier=region=ap,qualifier=env=prod\\\"\" }"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+	var missing *inject.MissingDependencyError
+	if !errors.As(err, &missing) || missing.Qualifiers["region"] != "ap" {
+		t.Fatal("expected a missing dependency error with the requested qualifiers")
+	}
+}
+
+func TestQualifierInvalid(t *testing.T) {
+	c := inject.NewContainer()
+	err := c.ProvideQualified(&TypeForQualifierS3{}, "region")
+	const msg = `invalid qualifier "region": expected key=value`
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+
+	for _, user := range []interface{}{
+		&struct {
+			A *TypeForQualifierS3 `inject:"qualifier=region"`
+		}{},
+		&struct {
+			A *TypeForQualifierS3 `inject:"private,qualifier=region=eu"`
+		}{},
+		&struct {
+			A *TypeForQualifierS3 `inject:"foo,qualifier=region=eu"`
+		}{},
+	} {
+		err := inject.Populate(user)
+		if !errors.Is(err, inject.ErrInvalidTag) {
+			t.Fatalf("expected an invalid tag error but got %v", err)
+		}
+	}
+}
+
+func TestUnqualifiedDuplicateStillRejected(t *testing.T) {
+	c := inject.NewContainer()
+	if err := c.ProvideQualified(&TypeForQualifierS3{}, "region=eu"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Provides(&TypeForQualifierS3{}); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Provides(&TypeForQualifierS3{})
+	var duplicate *inject.DuplicateProvideError
+	if !errors.As(err, &duplicate) {
+		t.Fatalf("expected a duplicate provide error but got %v", err)
+	}
+}
+
+type TypeForQualifierClient struct {
+	S3 *TypeForQualifierS3
+}
+
+func TestUnqualifiedConstructorArgAmbiguous(t *testing.T) {
+	c, _, _, _ := newTypeForQualifierContainer(t)
+	err := c.ProvideConstructor(func(s3 *TypeForQualifierS3) *TypeForQualifierClient {
+		return &TypeForQualifierClient{S3: s3}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = inject.Resolve[*TypeForQualifierClient](c)
+	var ambiguous *inject.AmbiguousDependencyError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 3 {
+		t.Fatalf("expected an unqualified constructor argument to be ambiguous but got %v", err)
+	}
+
+	var lazy struct {
+		S3 inject.Lazy[*TypeForQualifierS3] `inject:""`
+	}
+	c, _, _, _ = newTypeForQualifierContainer(t)
+	if err := c.Provides(&lazy); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lazy.S3.Get(); !errors.As(err, &ambiguous) {
+		t.Fatalf("expected a lazy unqualified value to be ambiguous but got %v", err)
+	}
+}
+
+func TestUnqualifiedPreferredByResolve(t *testing.T) {
+	c, _, _, _ := newTypeForQualifierContainer(t)
+	plain := &TypeForQualifierS3{region: "local"}
+	var user struct {
+		Store TypeForQualifierStore `inject:""`
+	}
+	if err := c.Provides(plain, &user); err != nil {
+		t.Fatal(err)
+	}
+	err := c.ProvideConstructor(func(s3 *TypeForQualifierS3) *TypeForQualifierClient {
+		return &TypeForQualifierClient{S3: s3}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if user.Store != plain {
+		t.Fatal("expected an interface field to prefer the unqualified value")
+	}
+
+	s3, err := inject.Resolve[*TypeForQualifierS3](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s3 != plain {
+		t.Fatal("expected Resolve to prefer the unqualified value")
+	}
+	store, err := inject.Resolve[TypeForQualifierStore](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store != plain {
+		t.Fatal("expected Resolve to prefer the unqualified value for an interface")
+	}
+	client, err := inject.Resolve[*TypeForQualifierClient](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.S3 != plain {
+		t.Fatal("expected a constructor argument to prefer the unqualified value")
+	}
+}
@@ -0,0 +1,166 @@
+package inject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// RequestScope 一次请求的作用域。Request作用域的bean在作用域中第一次被需要时创建，
+// 注入容器中的单例，并在Close时被释放。作用域可以被多个goroutine同时使用。
+type RequestScope struct {
+	container *Container
+	mu        sync.Mutex // 保护graph和closed
+	graph     *Graph
+	closed    bool
+}
+
+type scopeKey struct{}
+
+// Scope 为一次请求创建作用域，并返回携带该作用域的ctx。
+// 调用方必须在请求结束时调用RequestScope.Close释放作用域中的bean。
+func (c *Container) Scope(ctx context.Context) (context.Context, *RequestScope) {
+	c.mu.Lock()
+	graph := c.graph.NewChild()
+	c.mu.Unlock()
+	graph.scoped = true
+
+	s := &RequestScope{container: c, graph: graph}
+	graph.locker = scopeLocker{s}
+	return context.WithValue(ctx, scopeKey{}, s), s
+}
+
+// ScopeFromContext 返回ctx携带的请求作用域，没有时返回nil。
+func ScopeFromContext(ctx context.Context) *RequestScope {
+	s, _ := ctx.Value(scopeKey{}).(*RequestScope)
+	return s
+}
+
+// ResolveScoped 从ctx携带的请求作用域中取出唯一一个可分配给T的对象，
+// 作用域中找不到时在容器中查找。
+func ResolveScoped[T any](ctx context.Context) (T, error) {
+	var zero T
+	s := ScopeFromContext(ctx)
+	if s == nil {
+		return zero, errors.New("no request scope in context")
+	}
+	o, err := s.resolve(typeOf[T]())
+	if err != nil {
+		return zero, err
+	}
+	return o.Value.(T), nil
+}
+
+// resolve 在作用域中查找可分配给t的对象，需要时创建Request作用域的bean。
+func (s *RequestScope) resolve(t reflect.Type) (*Object, error) {
+	var o *Object
+	err := s.graph.locked(func() error {
+		if s.closed {
+			return errors.New("request scope is closed")
+		}
+		if err := s.container.expect("resolve request scoped beans", StatePopulated, StateStarted); err != nil {
+			return err
+		}
+		var err error
+		o, err = s.graph.resolveAndPopulate(t)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Close 按与创建相反的顺序调用作用域中bean的Stop和Close，之后作用域不能再使用。
+// 即使某个bean释放失败，其余的bean仍会被释放，返回遇到的第一个错误。
+func (s *RequestScope) Close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	var objects []*Object
+	for _, o := range s.graph.unnamed {
+		if o.Scope == Request {
+			objects = append(objects, o)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].seq > objects[j].seq
+	})
+
+	var first error
+	for _, o := range objects {
+		if err := release(ctx, o); err != nil && first == nil {
+			first = err
+		}
+		if s.graph.Logger != nil {
+			s.graph.Logger.Info("released %v", o)
+		}
+	}
+	return first
+}
+
+// release 调用o的Stop和Close。
+func release(ctx context.Context, o *Object) error {
+	var first error
+	if stopper, ok := o.Value.(Stopper); ok {
+		if err := stopper.Stop(ctx); err != nil {
+			first = fmt.Errorf("failed to stop %v: %w", o, err)
+		}
+	}
+	if closer, ok := o.Value.(io.Closer); ok {
+		if err := closer.Close(); err != nil && first == nil {
+			first = fmt.Errorf("failed to close %v: %w", o, err)
+		}
+	}
+	return first
+}
+
+// createInOwner 在请求作用域之外最近的依赖图中创建类型为t的单例并完成注入。
+// 这样它与构造函数创建的单例一样由容器管理并在请求之间共享，而不是在每个作用域中重新创建且永远不被释放。
+func (g *Graph) createInOwner(t reflect.Type) (*Object, error) {
+	owner := g.parent
+	for owner.scoped {
+		owner = owner.parent
+	}
+	o := &Object{
+		Value:   reflect.New(t.Elem()).Interface(),
+		created: true,
+	}
+	n := len(owner.unnamed)
+	if err := owner.Provide(o); err != nil {
+		return nil, err
+	}
+	if err := owner.populateExplicit(o); err != nil {
+		return nil, err
+	}
+	if err := owner.populateInterfaces(n); err != nil {
+		return nil, err
+	}
+	if err := owner.collectedErrors(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// scopeLocker 使作用域中的依赖图在Lazy和Provider查找依赖时锁定作用域和容器。
+type scopeLocker struct {
+	s *RequestScope
+}
+
+func (l scopeLocker) Lock() {
+	l.s.mu.Lock()
+	l.s.container.lockAll()
+}
+
+func (l scopeLocker) Unlock() {
+	l.s.container.unlockAll()
+	l.s.mu.Unlock()
+}
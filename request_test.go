@@ -0,0 +1,278 @@
+package inject_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeForRequestDB struct{}
+
+type TypeForRequestTx struct {
+	DB       *TypeForRequestDB `inject:""`
+	Inits    int
+	Stopped  bool
+	Closed   bool
+	released *[]string
+}
+
+func (tx *TypeForRequestTx) Init(ctx context.Context) error {
+	tx.Inits++
+	return nil
+}
+
+func (tx *TypeForRequestTx) Stop(ctx context.Context) error {
+	tx.Stopped = true
+	return nil
+}
+
+func (tx *TypeForRequestTx) Close() error {
+	tx.Closed = true
+	if tx.released != nil {
+		*tx.released = append(*tx.released, "tx")
+	}
+	return nil
+}
+
+type TypeForRequestRepo struct {
+	Tx *TypeForRequestTx `inject:""`
+	DB *TypeForRequestDB `inject:""`
+}
+
+type TypeForRequestHandler struct {
+	Repo *TypeForRequestRepo `inject:""`
+	Tx   *TypeForRequestTx   `inject:""`
+}
+
+func newTypeForRequestContainer(t *testing.T) (*inject.Container, *TypeForRequestDB) {
+	c := inject.NewContainer()
+	db := &TypeForRequestDB{}
+	if err := c.Provides(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideRequestScoped((*TypeForRequestTx)(nil), (*TypeForRequestRepo)(nil), (*TypeForRequestHandler)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	return c, db
+}
+
+func TestRequestScope(t *testing.T) {
+	c, db := newTypeForRequestContainer(t)
+	ctx, scope := c.Scope(context.Background())
+
+	handler, err := inject.ResolveScoped[*TypeForRequestHandler](ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handler.Tx == nil || handler.Repo.Tx != handler.Tx {
+		t.Fatal("expected one transaction shared within the request")
+	}
+	if handler.Repo.DB != db || handler.Tx.DB != db {
+		t.Fatal("expected the singleton db to be injected into request scoped beans")
+	}
+	if handler.Tx.Inits != 1 {
+		t.Fatalf("expected Init to run once but it ran %d times", handler.Tx.Inits)
+	}
+	again, err := inject.ResolveScoped[*TypeForRequestTx](ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != handler.Tx {
+		t.Fatal("expected the same transaction for the same request")
+	}
+
+	otherCtx, other := c.Scope(context.Background())
+	otherTx, err := inject.ResolveScoped[*TypeForRequestTx](otherCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherTx == handler.Tx {
+		t.Fatal("expected a new transaction for another request")
+	}
+
+	if err := scope.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !handler.Tx.Stopped || !handler.Tx.Closed {
+		t.Fatal("expected the transaction to be stopped and closed with its scope")
+	}
+	if otherTx.Closed {
+		t.Fatal("expected other scopes to be left alone")
+	}
+	if err := other.Close(otherCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = inject.ResolveScoped[*TypeForRequestTx](ctx)
+	const msg = "request scope is closed"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+}
+
+type TypeForRequestHelper struct {
+	Started bool
+	Stopped bool
+}
+
+func (h *TypeForRequestHelper) Start(ctx context.Context) error {
+	h.Started = true
+	return nil
+}
+
+func (h *TypeForRequestHelper) Stop(ctx context.Context) error {
+	h.Stopped = true
+	return nil
+}
+
+type TypeForRequestHelped struct {
+	Helper *TypeForRequestHelper `inject:""`
+}
+
+func TestRequestScopeCreatesSingletonsInContainer(t *testing.T) {
+	c := inject.NewContainer()
+	if err := c.ProvideRequestScoped((*TypeForRequestHelped)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, scope := c.Scope(context.Background())
+	helped, err := inject.ResolveScoped[*TypeForRequestHelped](ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := scope.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	otherCtx, other := c.Scope(context.Background())
+	otherHelped, err := inject.ResolveScoped[*TypeForRequestHelped](otherCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Close(otherCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	helper := helped.Helper
+	if helper == nil || otherHelped.Helper != helper {
+		t.Fatal("expected the helper to be a singleton shared between requests")
+	}
+	if !helper.Started || helper.Stopped {
+		t.Fatal("expected the helper to be started by the container and to outlive the requests")
+	}
+	if err := c.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !helper.Stopped {
+		t.Fatal("expected the helper to be stopped with the container")
+	}
+}
+
+func TestRequestScopeOutsideOfScope(t *testing.T) {
+	c, _ := newTypeForRequestContainer(t)
+	_, err := inject.Resolve[*TypeForRequestTx](c)
+	const msg = "request scoped type *inject_test.TypeForRequestTx cannot be used outside of a request scope"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+
+	_, err = inject.ResolveScoped[*TypeForRequestTx](context.Background())
+	if err == nil || err.Error() != "no request scope in context" {
+		t.Fatalf("expected a missing scope error but got %v", err)
+	}
+}
+
+func TestRequestScopedDependencyOfSingleton(t *testing.T) {
+	c := inject.NewContainer()
+	var singleton struct {
+		Tx *TypeForRequestTx `inject:""`
+	}
+	if err := c.Provides(&singleton); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideRequestScoped((*TypeForRequestTx)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Populate()
+	if err == nil || !strings.Contains(err.Error(), "cannot be used outside of a request scope") {
+		t.Fatalf("expected singletons not to depend on request scoped beans but got %v", err)
+	}
+}
+
+func TestRequestScopeBeforePopulate(t *testing.T) {
+	c := inject.NewContainer()
+	ctx, _ := c.Scope(context.Background())
+	_, err := inject.ResolveScoped[*TypeForRequestDB](ctx)
+	expectError(t, err, "cannot resolve request scoped beans when the container is configuring")
+}
+
+type TypeForRequestFailingCloser struct {
+	released *[]string
+}
+
+func (f *TypeForRequestFailingCloser) Close() error {
+	*f.released = append(*f.released, "failing")
+	return errors.New("boom")
+}
+
+func TestRequestScopeCloseOrder(t *testing.T) {
+	var released []string
+	c := inject.NewContainer()
+	err := c.ProvideRequestConstructor(func() *TypeForRequestFailingCloser {
+		return &TypeForRequestFailingCloser{released: &released}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.ProvideRequestConstructor(func(f *TypeForRequestFailingCloser) *TypeForRequestTx {
+		return &TypeForRequestTx{released: &released}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, scope := c.Scope(context.Background())
+	if _, err := inject.ResolveScoped[*TypeForRequestTx](ctx); err != nil {
+		t.Fatal(err)
+	}
+	err = scope.Close(ctx)
+	const msg = "failed to close *inject_test.TypeForRequestFailingCloser: boom"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+	if strings.Join(released, ",") != "tx,failing" {
+		t.Fatalf("expected dependents to be released first but got %v", released)
+	}
+}
+
+func TestRequestScopeConcurrent(t *testing.T) {
+	c, _ := newTypeForRequestContainer(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, scope := c.Scope(context.Background())
+			defer scope.Close(ctx)
+			if _, err := inject.ResolveScoped[*TypeForRequestHandler](ctx); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,123 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Resolve 从容器中取出唯一一个可分配给T的未命名对象。
+// T可以是结构体指针，也可以是接口类型；找不到或找到多个时返回错误。
+func Resolve[T any](c *Container) (T, error) {
+	var zero T
+	var o *Object
+	err := c.graph.locked(func() error {
+		var err error
+		o, err = c.graph.resolveAndPopulate(typeOf[T]())
+		return err
+	})
+	if err != nil {
+		return zero, err
+	}
+	return o.Value.(T), nil
+}
+
+// ResolveNamed 从容器中取出指定名称的对象，该对象必须可以分配给T
+func ResolveNamed[T any](c *Container, name string) (T, error) {
+	var zero T
+	var o *Object
+	err := c.graph.locked(func() error {
+		var err error
+		o, err = c.graph.resolveNamed(name, typeOf[T]())
+		return err
+	})
+	if err != nil {
+		return zero, err
+	}
+	return o.Value.(T), nil
+}
+
+// ResolveQualified 从容器中取出唯一一个可分配给T且带有所有给定限定符的对象，限定符形如 "region=eu"。
+func ResolveQualified[T any](c *Container, qualifiers ...string) (T, error) {
+	var zero T
+	parsed, err := parseQualifiers(qualifiers)
+	if err != nil {
+		return zero, err
+	}
+	c.lockAll()
+	defer c.unlockAll()
+	t := typeOf[T]()
+	found := c.graph.qualified(t, parsed)
+	switch len(found) {
+	case 0:
+		return zero, &MissingDependencyError{Type: t, Qualifiers: parsed}
+	case 1:
+		return found[0].Value.(T), nil
+	}
+	return zero, &AmbiguousDependencyError{Type: t, Candidates: found}
+}
+
+// typeOf 返回T的静态类型，对接口类型同样有效。
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// resolveAndPopulate 查找可分配给t的对象，并对因此由构造函数新创建的对象完成注入。
+func (g *Graph) resolveAndPopulate(t reflect.Type) (*Object, error) {
+	n := len(g.unnamed)
+	o, err := g.resolve(t)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.populateInterfaces(n); err != nil {
+		return nil, err
+	}
+	if err := g.collectedErrors(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// resolve 在未命名对象中按pick的规则查找一个可分配给t的非私有对象，
+// 匹配规则与populateUnnamedInterface一致，t有装饰器时返回包装后的对象。没有现有对象时会尝试调用构造函数。
+func (g *Graph) resolve(t reflect.Type) (*Object, error) {
+	candidate, ambiguous := pick(t, g.assignable(t))
+	if ambiguous != nil {
+		return nil, &AmbiguousDependencyError{Type: t, Candidates: ambiguous}
+	}
+	if candidate != nil {
+		return g.decorate(t, candidate)
+	}
+
+	constructor, err := g.findConstructor(t)
+	if err != nil {
+		return nil, err
+	}
+	if constructor != nil {
+		constructed, err := g.construct(constructor)
+		if err != nil {
+			return nil, err
+		}
+		return g.decorate(t, constructed)
+	}
+	return nil, &MissingDependencyError{Type: t}
+}
+
+// resolveNamed 查找指定名称的对象并检查其是否可以分配给t，t为接口时返回包装后的对象。
+func (g *Graph) resolveNamed(name string, t reflect.Type) (*Object, error) {
+	existing := g.lookupNamed(name)
+	if existing == nil {
+		return nil, &MissingDependencyError{Type: t, Name: name}
+	}
+	if !existing.reflectType.AssignableTo(t) {
+		return nil, fmt.Errorf(
+			"object named %s of type %s is not assignable to type %s",
+			name,
+			existing.reflectType,
+			t,
+		)
+	}
+	if t.Kind() == reflect.Interface {
+		return g.decorate(t, existing)
+	}
+	return existing, nil
+}
@@ -0,0 +1,163 @@
+package inject_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ComingCL/go-inject"
+)
+
+func TestResolveStructPointer(t *testing.T) {
+	c := inject.NewContainer()
+	a := &TypeAnswerStruct{answer: 42}
+	if err := c.Provides(a, &TypeNestedStruct{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := inject.Resolve[*TypeAnswerStruct](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != a {
+		t.Fatal("did not resolve the provided instance")
+	}
+
+	nested, err := inject.Resolve[*TypeNestedStruct](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nested.A != a {
+		t.Fatal("resolved instance was not populated")
+	}
+}
+
+func TestResolveInterface(t *testing.T) {
+	c := inject.NewContainer()
+	a := &TypeAnswerStruct{answer: 42}
+	if err := c.Provides(a); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := inject.Resolve[Answerable](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual.Answer() != 42 {
+		t.Fatalf("expected 42 but got %d", actual.Answer())
+	}
+}
+
+func TestResolveMissing(t *testing.T) {
+	c := inject.NewContainer()
+	_, err := inject.Resolve[Answerable](c)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "found no assignable value for type inject_test.Answerable"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+func TestResolveAmbiguous(t *testing.T) {
+	c := inject.NewContainer()
+	if err := c.Provides(&TypeAnswerStruct{}, &TypeNestedStruct{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := inject.Resolve[Answerable](c)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "found two assignable values for type inject_test.Answerable. one type *inject_test.TypeAnswerStruct with value &{0 0} and another type *inject_test.TypeNestedStruct with value"
+	if !strings.HasPrefix(err.Error(), msg) {
+		t.Fatalf("expected prefix:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+func TestResolveIgnoresPrivate(t *testing.T) {
+	c := inject.NewContainer()
+	var v struct {
+		A *TypeAnswerStruct `inject:"private"`
+	}
+	if err := c.Provides(&v); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := inject.Resolve[*TypeAnswerStruct](c); err == nil {
+		t.Fatal("expected error for private object")
+	}
+}
+
+func TestResolveNamed(t *testing.T) {
+	c := inject.NewContainer()
+	a := &TypeAnswerStruct{answer: 1}
+	b := &TypeAnswerStruct{answer: 2}
+	if err := c.ProvideWithName("a", a); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvideWithName("b", b); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := inject.ResolveNamed[Answerable](c, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != b {
+		t.Fatal("did not resolve the named instance")
+	}
+}
+
+func TestResolveNamedValue(t *testing.T) {
+	c := inject.NewContainer()
+	if err := c.ProvideWithName("port", 8080); err != nil {
+		t.Fatal(err)
+	}
+
+	port, err := inject.ResolveNamed[int](c, "port")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port != 8080 {
+		t.Fatalf("expected 8080 but got %d", port)
+	}
+}
+
+func TestResolveNamedMissing(t *testing.T) {
+	c := inject.NewContainer()
+	_, err := inject.ResolveNamed[Answerable](c, "foo")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "did not find object named foo"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+func TestResolveNamedNotAssignable(t *testing.T) {
+	c := inject.NewContainer()
+	if err := c.ProvideWithName("foo", &TypeAnswerStruct{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := inject.ResolveNamed[*TypeNestedStruct](c, "foo")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	const msg = "object named foo of type *inject_test.TypeAnswerStruct is not assignable to type *inject_test.TypeNestedStruct"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
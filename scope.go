@@ -0,0 +1,131 @@
+package inject
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Scope 决定bean的实例在注入点之间如何共享
+type Scope int
+
+const (
+	Singleton Scope = iota // 默认的，所有注入点共享同一个实例
+	Prototype              // 每个注入点和每次Resolve都得到一个新创建并完成注入的实例
+	Request                // 在每个请求作用域中第一次被需要时创建，作用域结束时释放
+)
+
+func (s Scope) String() string {
+	switch s {
+	case Singleton:
+		return "singleton"
+	case Prototype:
+		return "prototype"
+	case Request:
+		return "request"
+	}
+	return fmt.Sprintf("Scope(%d)", int(s))
+}
+
+// provideScoped 将非单例对象o注册为一个创建新实例的构造函数，o.Value只用于确定类型，可以是nil指针。
+func (g *Graph) provideScoped(o *Object) error {
+	if o.Name != "" {
+		return fmt.Errorf("%v object %v must be unnamed", o.Scope, o)
+	}
+	if o.reflectType == nil || !isStructPtr(o.reflectType) {
+		return fmt.Errorf(
+			"expected %v object value to be a pointer to a struct but got type %s",
+			o.Scope,
+			o.reflectType,
+		)
+	}
+
+	elemType := o.reflectType.Elem()
+	fnType := reflect.FuncOf(nil, []reflect.Type{o.reflectType}, false)
+	fn := reflect.MakeFunc(fnType, func([]reflect.Value) []reflect.Value {
+		return []reflect.Value{reflect.New(elemType)}
+	})
+	return g.ProvideConstructor(&Constructor{Func: fn.Interface(), Scope: o.Scope})
+}
+
+// initScoped 在原型或请求作用域的实例完成注入后调用其Init。
+// 这些实例不参与容器的生命周期，所以Init只在这里调用一次；计划中创建的实例在Apply时调用。
+// 由容器管理的依赖图把Init推迟到释放锁之后，这样Init中可以通过Resolve取出其他bean。
+func (g *Graph) initScoped(o *Object) error {
+	o.Complete = true
+	if _, ok := o.Value.(Initializer); !ok {
+		return nil
+	}
+	if g.plan != nil {
+		g.plan.inits = append(g.plan.inits, o)
+		return nil
+	}
+	return g.runScopedInits([]*Object{o})
+}
+
+// runScopedInits 调用objects的Init，由容器管理的依赖图把它们推迟到释放锁之后。
+func (g *Graph) runScopedInits(objects []*Object) error {
+	if r := g.root(); r.deferInits {
+		r.inits = append(r.inits, objects...)
+		return nil
+	}
+	return g.runInits(objects)
+}
+
+// root 返回g最上层的祖先，没有父级时返回g本身。
+func (g *Graph) root() *Graph {
+	for g.parent != nil {
+		g = g.parent
+	}
+	return g
+}
+
+// locked 在持有g.locker时调用fn，释放锁之后再调用期间被推迟的Init，
+// 并把容器启动后新创建的单例交给各自的容器启动。fn失败时被推迟的Init不会被调用，
+// 新创建的单例留到下一次成功的调用时再启动。
+func (g *Graph) locked(fn func() error) error {
+	if g.locker == nil {
+		return fn()
+	}
+	g.locker.Lock()
+	err := fn()
+	r := g.root()
+	inits := r.inits
+	r.inits = nil
+	var trackers []*lifecycleTracker
+	var fresh [][]*Object
+	for p := g; p != nil && err == nil; p = p.parent {
+		if objects := p.fresh(); len(objects) > 0 {
+			trackers = append(trackers, p.tracker)
+			fresh = append(fresh, objects)
+		}
+	}
+	g.locker.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := g.runInits(inits); err != nil {
+		return err
+	}
+	// 祖先中的单例先启动，因为子依赖图中的对象可能依赖它们。
+	for i := len(trackers) - 1; i >= 0; i-- {
+		if err := trackers[i].adopt(fresh[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runInits 依次调用objects的Init。
+func (g *Graph) runInits(objects []*Object) error {
+	for _, o := range objects {
+		if err := o.Value.(Initializer).Init(context.Background()); err != nil {
+			return fmt.Errorf("failed to init %v: %w", o, err)
+		}
+		if g.Logger != nil {
+			g.Logger.Info("initialized %v", o)
+		}
+	}
+	return nil
+}
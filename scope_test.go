@@ -0,0 +1,249 @@
+package inject_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ComingCL/go-inject"
+)
+
+type TypeForScopeGreeter interface {
+	Greet() string
+}
+
+type TypeForScopeSession struct {
+	Answer  *TypeAnswerStruct   `inject:""`
+	Greeter TypeForScopeGreeter `inject:""`
+	Inits   int
+}
+
+func (s *TypeForScopeSession) Init(ctx context.Context) error {
+	if s.Answer == nil || s.Greeter == nil {
+		return errors.New("session was initialized before it was populated")
+	}
+	s.Inits++
+	return nil
+}
+
+type TypeForScopeHello struct{}
+
+func (*TypeForScopeHello) Greet() string { return "hello" }
+
+type TypeForScopeHandler struct {
+	First  *TypeForScopeSession `inject:""`
+	Second *TypeForScopeSession `inject:""`
+}
+
+func TestPrototypeObject(t *testing.T) {
+	c := inject.NewContainer()
+	var handler TypeForScopeHandler
+	answer := &TypeAnswerStruct{}
+	if err := c.Provides(&handler, answer, &TypeForScopeHello{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvidePrototype((*TypeForScopeSession)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if handler.First == nil || handler.Second == nil {
+		t.Fatal("prototype fields were not populated")
+	}
+	if handler.First == handler.Second {
+		t.Fatal("expected a new instance for every injection point")
+	}
+	for _, s := range []*TypeForScopeSession{handler.First, handler.Second} {
+		if s.Answer != answer {
+			t.Fatal("prototype instance was not populated with the shared answer")
+		}
+		if s.Inits != 1 {
+			t.Fatalf("expected Init to run once but it ran %d times", s.Inits)
+		}
+	}
+
+	resolved, err := inject.Resolve[*TypeForScopeSession](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := inject.Resolve[*TypeForScopeSession](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved == again || resolved == handler.First || resolved == handler.Second {
+		t.Fatal("expected a new instance for every Resolve call")
+	}
+	if resolved.Greeter == nil || resolved.Inits != 1 {
+		t.Fatal("resolved prototype instance was not fully populated and initialized")
+	}
+}
+
+// typeForScopeContainer 供TypeForScopeResolving的Init回到容器中查找依赖
+var typeForScopeContainer *inject.Container
+
+type TypeForScopeResolving struct {
+	Answer *TypeAnswerStruct
+}
+
+func (r *TypeForScopeResolving) Init(ctx context.Context) error {
+	answer, err := inject.Resolve[*TypeAnswerStruct](typeForScopeContainer)
+	r.Answer = answer
+	return err
+}
+
+func TestPrototypeInitResolves(t *testing.T) {
+	c := inject.NewContainer()
+	typeForScopeContainer = c
+	answer := &TypeAnswerStruct{}
+	var user struct {
+		Resolving *TypeForScopeResolving `inject:""`
+	}
+	if err := c.Provides(&user, answer); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvidePrototype((*TypeForScopeResolving)(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		if err := c.Populate(); err != nil {
+			done <- err
+			return
+		}
+		resolved, err := inject.Resolve[*TypeForScopeResolving](c)
+		if err == nil && resolved.Answer != answer {
+			err = errors.New("expected Init of the resolved instance to resolve the answer")
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("prototype Init calling Resolve deadlocked")
+	}
+	if user.Resolving.Answer != answer {
+		t.Fatal("expected Init of the injected instance to resolve the answer")
+	}
+}
+
+type TypeForScopeCounter struct {
+	N int
+}
+
+type TypeForScopeCounterUsers struct {
+	A *TypeForScopeCounter   `inject:""`
+	B *TypeForScopeCounter   `inject:""`
+	C []*TypeForScopeCounter `inject:""`
+}
+
+func TestPrototypeConstructor(t *testing.T) {
+	c := inject.NewContainer()
+	calls := 0
+	err := c.ProvidePrototypeConstructor(func() *TypeForScopeCounter {
+		calls++
+		return &TypeForScopeCounter{N: calls}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var users TypeForScopeCounterUsers
+	if err := c.Provides(&users); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 3 {
+		t.Fatalf("expected the constructor to be called 3 times but it was called %d times", calls)
+	}
+	if users.A.N == users.B.N {
+		t.Fatal("expected different instances for different fields")
+	}
+	if len(users.C) != 1 || users.C[0] == users.A || users.C[0] == users.B {
+		t.Fatal("expected a new instance in the slice")
+	}
+}
+
+type TypeForScopeStarter struct {
+	Started bool
+}
+
+func (s *TypeForScopeStarter) Start(ctx context.Context) error {
+	s.Started = true
+	return nil
+}
+
+type TypeForScopeStarterUser struct {
+	Starter *TypeForScopeStarter `inject:""`
+}
+
+func TestPrototypeNotInLifecycle(t *testing.T) {
+	c := inject.NewContainer()
+	var user TypeForScopeStarterUser
+	if err := c.Provides(&user); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProvidePrototype(&TypeForScopeStarter{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Populate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if user.Starter.Started {
+		t.Fatal("prototype instance should not be started by the container")
+	}
+}
+
+type TypeForScopeCycle struct {
+	Next *TypeForScopeCycle `inject:""`
+}
+
+func TestPrototypeCycle(t *testing.T) {
+	var g inject.Graph
+	if err := g.Provide(&inject.Object{Value: &TypeForScopeCycle{}, Scope: inject.Prototype}); err != nil {
+		t.Fatal(err)
+	}
+	var user struct {
+		Cycle *TypeForScopeCycle `inject:""`
+	}
+	if err := g.Provide(&inject.Object{Value: &user}); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Populate()
+	var cycle *inject.CycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("expected a cycle error but got %v", err)
+	}
+}
+
+func TestPrototypeMustBeUnnamed(t *testing.T) {
+	var g inject.Graph
+	err := g.Provide(&inject.Object{Name: "session", Value: &TypeForScopeSession{}, Scope: inject.Prototype})
+	const msg = "prototype object *inject_test.TypeForScopeSession named session must be unnamed"
+	if err == nil || err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%v", msg, err)
+	}
+}
+
+func TestPrototypeConflictsWithSingleton(t *testing.T) {
+	var g inject.Graph
+	if err := g.Provide(&inject.Object{Value: &TypeForScopeCounter{}}); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Provide(&inject.Object{Value: &TypeForScopeCounter{}, Scope: inject.Prototype})
+	var dup *inject.DuplicateProvideError
+	if !errors.As(err, &dup) {
+		t.Fatalf("expected a duplicate provide error but got %v", err)
+	}
+}
@@ -2,10 +2,12 @@ package inject
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 )
 
-var errInvalidTag = errors.New("invalid tag")
+// ErrInvalidTag 表示结构体标签的格式错误，可以通过errors.Is从InvalidTagError中识别。
+var ErrInvalidTag = errors.New("invalid tag")
 
 // Extract 提取给定名称的引用值，如果找到则返回它。
 // found布尔值有助于区分默认空字符串的"空且找到"与"空且未找到"的性质。
@@ -28,7 +30,7 @@ func Extract(name, tag string) (found bool, value string, err error) {
 			i++
 		}
 		if i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
-			return false, "", errInvalidTag
+			return false, "", ErrInvalidTag
 		}
 		foundName := tag[:i]
 		tag = tag[i+1:]
@@ -42,7 +44,7 @@ func Extract(name, tag string) (found bool, value string, err error) {
 			i++
 		}
 		if i >= len(tag) {
-			return false, "", errInvalidTag
+			return false, "", ErrInvalidTag
 		}
 		qValue := tag[:i+1]
 		tag = tag[i+1:]
@@ -50,7 +52,7 @@ func Extract(name, tag string) (found bool, value string, err error) {
 		if foundName == name {
 			value, err = strconv.Unquote(qValue)
 			if err != nil {
-				return false, "", err
+				return false, "", fmt.Errorf("%w: %v", ErrInvalidTag, err)
 			}
 			return true, value, nil
 		}